@@ -1,15 +1,165 @@
 package config
 
 import (
+	"gopkg.in/ini.v1"
+
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 )
 
 type parseConfig struct {
-	INIPaths  []string `hcl:"ini_paths,optional" steampipe:"watch"`
-	JSONPaths []string `hcl:"json_paths,optional" steampipe:"watch"`
-	TOMLPaths []string `hcl:"toml_paths,optional" steampipe:"watch"`
-	XMLPaths  []string `hcl:"xml_paths,optional" steampipe:"watch"`
-	YMLPaths  []string `hcl:"yml_paths,optional" steampipe:"watch"`
+	ApachePaths           []string               `hcl:"apache_paths,optional" steampipe:"watch"`
+	CUEPaths              []string               `hcl:"cue_paths,optional" steampipe:"watch"`
+	DependencyPaths       []string               `hcl:"dependency_paths,optional" steampipe:"watch"`
+	DockerComposePaths    []string               `hcl:"docker_compose_paths,optional" steampipe:"watch"`
+	DockerfilePaths       []string               `hcl:"dockerfile_paths,optional" steampipe:"watch"`
+	GitHubWorkflowPaths   []string               `hcl:"github_workflow_paths,optional" steampipe:"watch"`
+	INIPaths              []string               `hcl:"ini_paths,optional" steampipe:"watch"`
+	JSONPaths             []string               `hcl:"json_paths,optional" steampipe:"watch"`
+	JSONDialect           string                 `hcl:"json_dialect,optional"`
+	JsonnetPaths          []string               `hcl:"jsonnet_paths,optional" steampipe:"watch"`
+	JSONLPaths            []string               `hcl:"jsonl_paths,optional" steampipe:"watch"`
+	KubernetesPaths       []string               `hcl:"kubernetes_paths,optional" steampipe:"watch"`
+	LockfilePaths         []string               `hcl:"lockfile_paths,optional" steampipe:"watch"`
+	MavenPaths            []string               `hcl:"maven_paths,optional" steampipe:"watch"`
+	NginxPaths            []string               `hcl:"nginx_paths,optional" steampipe:"watch"`
+	PlistPaths            []string               `hcl:"plist_paths,optional" steampipe:"watch"`
+	RegPaths              []string               `hcl:"reg_paths,optional" steampipe:"watch"`
+	SSHConfigPaths        []string               `hcl:"ssh_config_paths,optional" steampipe:"watch"`
+	SystemdPaths          []string               `hcl:"systemd_paths,optional" steampipe:"watch"`
+	TOMLPaths             []string               `hcl:"toml_paths,optional" steampipe:"watch"`
+	XMLPaths              []string               `hcl:"xml_paths,optional" steampipe:"watch"`
+	YMLPaths              []string               `hcl:"yml_paths,optional" steampipe:"watch"`
+	INIOptions            *iniOptions            `hcl:"ini_options,block"`
+	JsonnetOptions        *jsonnetOptions        `hcl:"jsonnet_options,block"`
+	CUEOptions            *cueOptions            `hcl:"cue_options,block"`
+	XMLOptions            *xmlOptions            `hcl:"xml_options,block"`
+	MergeStacks           []mergeStack           `hcl:"merge_stack,block"`
+	DockerComposeProjects []dockerComposeProject `hcl:"docker_compose_project,block"`
+}
+
+// dockerComposeProject is a Compose project made of several files, merged in
+// order as if passed to docker compose with -f.
+type dockerComposeProject struct {
+	Name    string   `hcl:"name,label"`
+	Files   []string `hcl:"files"`
+	EnvFile string   `hcl:"env_file,optional"`
+}
+
+// mergeStack is an ordered list of config file layers which are deep merged,
+// with later layers overriding earlier ones.
+type mergeStack struct {
+	Name          string   `hcl:"name,label"`
+	Paths         []string `hcl:"paths"`
+	ArrayMerge    string   `hcl:"array_merge,optional"`
+	ArrayMergeKey string   `hcl:"array_merge_key,optional"`
+}
+
+// iniOptions controls the dialect used when parsing INI files. Options map
+// directly to the go-ini LoadOptions of the same name.
+type iniOptions struct {
+	Insensitive                bool     `hcl:"insensitive,optional"`
+	InsensitiveSections        bool     `hcl:"insensitive_sections,optional"`
+	InsensitiveKeys            bool     `hcl:"insensitive_keys,optional"`
+	AllowBooleanKeys           bool     `hcl:"allow_boolean_keys,optional"`
+	AllowShadows               bool     `hcl:"allow_shadows,optional"`
+	AllowNonUniqueSections     bool     `hcl:"allow_non_unique_sections,optional"`
+	AllowPythonMultilineValues *bool    `hcl:"allow_python_multiline_values,optional"`
+	IgnoreInlineComment        bool     `hcl:"ignore_inline_comment,optional"`
+	SpaceBeforeInlineComment   bool     `hcl:"space_before_inline_comment,optional"`
+	SkipUnrecognizableLines    bool     `hcl:"skip_unrecognizable_lines,optional"`
+	UnparseableSections        []string `hcl:"unparseable_sections,optional"`
+	KeyValueDelimiters         string   `hcl:"key_value_delimiters,optional"`
+}
+
+// loadOptions returns the go-ini LoadOptions for the configured dialect.
+// Python-like multi-line values are allowed unless explicitly disabled.
+func (o *iniOptions) loadOptions() ini.LoadOptions {
+	if o == nil {
+		return ini.LoadOptions{AllowPythonMultilineValues: true}
+	}
+	opts := ini.LoadOptions{
+		Insensitive:                o.Insensitive,
+		InsensitiveSections:        o.InsensitiveSections,
+		InsensitiveKeys:            o.InsensitiveKeys,
+		AllowBooleanKeys:           o.AllowBooleanKeys,
+		AllowShadows:               o.AllowShadows,
+		AllowNonUniqueSections:     o.AllowNonUniqueSections,
+		AllowPythonMultilineValues: true,
+		IgnoreInlineComment:        o.IgnoreInlineComment,
+		SpaceBeforeInlineComment:   o.SpaceBeforeInlineComment,
+		SkipUnrecognizableLines:    o.SkipUnrecognizableLines,
+		UnparseableSections:        o.UnparseableSections,
+		KeyValueDelimiters:         o.KeyValueDelimiters,
+	}
+	// Keep every occurrence of a repeated key, even when values are identical
+	opts.AllowDuplicateShadowValues = o.AllowShadows
+	if o.AllowPythonMultilineValues != nil {
+		opts.AllowPythonMultilineValues = *o.AllowPythonMultilineValues
+	}
+	return opts
+}
+
+// jsonnetOptions controls the evaluation of Jsonnet files. Library paths are
+// searched for imports which are not found relative to the importing file, as
+// with jsonnet -J.
+type jsonnetOptions struct {
+	LibraryPaths []string          `hcl:"library_paths,optional"`
+	ExtVars      map[string]string `hcl:"ext_vars,optional"`
+	ExtCode      map[string]string `hcl:"ext_code,optional"`
+	TLAVars      map[string]string `hcl:"tla_vars,optional"`
+	TLACode      map[string]string `hcl:"tla_code,optional"`
+}
+
+// cueOptions controls the evaluation of CUE files. Tags are injected into the
+// fields with a matching @tag attribute, as with cue export -t.
+type cueOptions struct {
+	Tags       map[string]string `hcl:"tags,optional"`
+	ModuleRoot string            `hcl:"module_root,optional"`
+}
+
+// xmlOptions controls how XML files are converted to JSON. Entities declared
+// in the DOCTYPE of a file are only expanded if enabled, and external
+// entities are never loaded.
+type xmlOptions struct {
+	AttributePrefix *string `hcl:"attribute_prefix,optional"`
+	TextKey         string  `hcl:"text_key,optional"`
+	KeepNamespaces  bool    `hcl:"keep_namespaces,optional"`
+	CastValues      bool    `hcl:"cast_values,optional"`
+	Strict          *bool   `hcl:"strict,optional"`
+	MaxDepth        int     `hcl:"max_depth,optional"`
+	ExpandEntities  bool    `hcl:"expand_entities,optional"`
+}
+
+// attributePrefix returns the prefix of attribute keys, "-" unless configured.
+// An empty prefix may be configured.
+func (o *xmlOptions) attributePrefix() string {
+	if o == nil || o.AttributePrefix == nil {
+		return "-"
+	}
+	return *o.AttributePrefix
+}
+
+// textKey returns the key of the text of elements with attributes or child
+// elements, "#text" unless configured.
+func (o *xmlOptions) textKey() string {
+	if o == nil || o.TextKey == "" {
+		return "#text"
+	}
+	return o.TextKey
+}
+
+// strict returns whether XML files must be well-formed. Files are parsed
+// strictly unless explicitly disabled.
+func (o *xmlOptions) strict() bool {
+	return o == nil || o.Strict == nil || *o.Strict
+}
+
+// maxDepth returns the maximum nesting of elements.
+func (o *xmlOptions) maxDepth() int {
+	if o == nil || o.MaxDepth <= 0 {
+		return xmlDefaultMaxDepth
+	}
+	return o.MaxDepth
 }
 
 func ConfigInstance() interface{} {
@@ -0,0 +1,557 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"gopkg.in/yaml.v3"
+)
+
+// JSON dialects supported by the json_dialect config argument.
+const (
+	jsonDialectStrict = "strict"
+	jsonDialectJSONC  = "jsonc"
+	jsonDialectJSON5  = "json5"
+)
+
+// jsonDialect returns the dialect to parse a JSON file with. Unless set by
+// the json_dialect config argument, .json5 files are parsed as JSON5 and all
+// other files as JSONC, which accepts any valid JSON file.
+func jsonDialect(d *plugin.QueryData, path string) (string, error) {
+	dialect := GetConfig(d.Connection).JSONDialect
+	switch dialect {
+	case "", "auto":
+		if strings.EqualFold(filepath.Ext(path), ".json5") {
+			return jsonDialectJSON5, nil
+		}
+		return jsonDialectJSONC, nil
+	case jsonDialectStrict, jsonDialectJSONC, jsonDialectJSON5:
+		return dialect, nil
+	}
+	return "", fmt.Errorf("invalid json_dialect %q, must be one of auto, strict, jsonc or json5", dialect)
+}
+
+// loadJSONNode parses the content of a JSON file in the dialect configured for
+// its path.
+func loadJSONNode(d *plugin.QueryData, path string, content []byte) (*yaml.Node, error) {
+	dialect, err := jsonDialect(d, path)
+	if err != nil {
+		return nil, err
+	}
+	return parseJSONNode(content, dialect)
+}
+
+// parseJSONNode parses JSON content into a YAML document node, with the same
+// line numbers, columns and tags as the YAML decoder would produce. Comments
+// on the lines before a key are set as the head comment of the key, and
+// comments at the end of the line of a value as its line comment.
+//
+// The jsonc dialect adds comments and trailing commas to strict JSON, and the
+// json5 dialect also adds unquoted keys, single quoted and multi-line strings,
+// hexadecimal numbers, leading and trailing decimal points, explicit plus
+// signs, Infinity and NaN.
+func parseJSONNode(content []byte, dialect string) (*yaml.Node, error) {
+	p := &jsonParser{src: content, dialect: dialect, line: 1, column: 1}
+	// Skip a UTF-8 byte order mark
+	if bytes.HasPrefix(content, []byte("\uFEFF")) {
+		p.pos = 3
+	}
+
+	if err := p.skip(); err != nil {
+		return nil, err
+	}
+	if p.pos >= len(p.src) {
+		return nil, errors.New("unexpected end of file, expecting a value")
+	}
+	root, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.skip(); err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q after the root value", p.src[p.pos])
+	}
+
+	doc := &yaml.Node{Kind: yaml.DocumentNode, Line: 1, Column: 1, Content: []*yaml.Node{root}}
+	doc.FootComment = strings.Join(p.comments, "\n")
+	return doc, nil
+}
+
+// jsonMaxDepth is the maximum nesting of objects and arrays, which bounds the
+// recursion of the parser.
+const jsonMaxDepth = 10000
+
+type jsonParser struct {
+	src     []byte
+	dialect string
+	pos     int
+	line    int
+	column  int
+	// depth is the number of objects and arrays being parsed
+	depth int
+
+	// comments holds the comments on their own lines since the last key
+	comments []string
+	// last is the node ending on line lastLine, which comments at the end of
+	// that line are attached to
+	last     *yaml.Node
+	lastLine int
+}
+
+func (p *jsonParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d column %d: %s", p.line, p.column, fmt.Sprintf(format, args...))
+}
+
+// advance moves past n bytes, keeping track of lines and columns in runes.
+func (p *jsonParser) advance(n int) {
+	for ; n > 0 && p.pos < len(p.src); n-- {
+		c := p.src[p.pos]
+		p.pos++
+		switch {
+		case c == '\n':
+			p.line++
+			p.column = 1
+		case c < utf8.RuneSelf || c >= 0xC0:
+			p.column++
+		}
+	}
+}
+
+func (p *jsonParser) peek() byte {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+// skip moves past whitespace and comments, collecting the comments.
+func (p *jsonParser) skip() error {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			p.advance(1)
+			continue
+		case p.dialect == jsonDialectJSON5 && c >= utf8.RuneSelf && isJSON5Space(p.src[p.pos:]):
+			_, size := utf8.DecodeRune(p.src[p.pos:])
+			p.advance(size)
+			continue
+		case c != '/' || p.pos+1 >= len(p.src) || (p.src[p.pos+1] != '/' && p.src[p.pos+1] != '*'):
+			return nil
+		}
+
+		if p.dialect == jsonDialectStrict {
+			return p.errorf("comments are not allowed in strict JSON")
+		}
+		line := p.line
+		start := p.pos
+		if p.src[p.pos+1] == '/' {
+			end := bytes.IndexByte(p.src[p.pos:], '\n')
+			if end < 0 {
+				end = len(p.src) - p.pos
+			}
+			p.advance(end)
+		} else {
+			end := bytes.Index(p.src[p.pos+2:], []byte("*/"))
+			if end < 0 {
+				return p.errorf("unterminated comment")
+			}
+			p.advance(end + 4)
+		}
+		comment := strings.TrimRight(string(p.src[start:p.pos]), " \t\r\n")
+
+		if p.last != nil && line == p.lastLine {
+			if p.last.LineComment != "" {
+				p.last.LineComment += " "
+			}
+			p.last.LineComment += comment
+			continue
+		}
+		p.comments = append(p.comments, comment)
+	}
+	return nil
+}
+
+// end records the node ending at the current line, for line comments.
+func (p *jsonParser) end(node *yaml.Node) {
+	p.last = node
+	p.lastLine = p.line
+}
+
+func (p *jsonParser) value() (*yaml.Node, error) {
+	line, column := p.line, p.column
+	switch c := p.peek(); {
+	case c == '{':
+		return p.object()
+	case c == '[':
+		return p.array()
+	case c == '"' || (c == '\'' && p.dialect == jsonDialectJSON5):
+		s, err := p.string()
+		if err != nil {
+			return nil, err
+		}
+		node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s, Style: yaml.DoubleQuotedStyle, Line: line, Column: column}
+		p.end(node)
+		return node, nil
+	case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9') || (p.dialect == jsonDialectJSON5 && (c == 'I' || c == 'N')):
+		return p.number()
+	case c == 't' || c == 'f' || c == 'n':
+		word := p.identifier()
+		node := &yaml.Node{Kind: yaml.ScalarNode, Value: word, Line: line, Column: column}
+		switch word {
+		case "true", "false":
+			node.Tag = "!!bool"
+		case "null":
+			node.Tag = "!!null"
+		default:
+			return nil, fmt.Errorf("line %d column %d: unexpected %q", line, column, word)
+		}
+		p.end(node)
+		return node, nil
+	case c == 0:
+		return nil, p.errorf("unexpected end of file, expecting a value")
+	default:
+		return nil, p.errorf("unexpected %q, expecting a value", c)
+	}
+}
+
+func (p *jsonParser) object() (*yaml.Node, error) {
+	if p.depth >= jsonMaxDepth {
+		return nil, p.errorf("exceeded max nesting depth of %d", jsonMaxDepth)
+	}
+	p.depth++
+	defer func() { p.depth-- }()
+
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Style: yaml.FlowStyle, Line: p.line, Column: p.column}
+	p.advance(1)
+	p.end(node)
+
+	var lastKey *yaml.Node
+	for {
+		if err := p.skip(); err != nil {
+			return nil, err
+		}
+		if p.peek() == '}' {
+			break
+		}
+
+		// Keys are strings, or identifiers in JSON5
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Line: p.line, Column: p.column}
+		switch c := p.peek(); {
+		case c == '"' || (c == '\'' && p.dialect == jsonDialectJSON5):
+			s, err := p.string()
+			if err != nil {
+				return nil, err
+			}
+			key.Value = s
+			key.Style = yaml.DoubleQuotedStyle
+		case p.dialect == jsonDialectJSON5 && isJSON5IdentifierStart(c):
+			key.Value = p.identifier()
+		case c == 0:
+			return nil, p.errorf("unexpected end of file, expecting a key or }")
+		default:
+			return nil, p.errorf("unexpected %q, expecting a key or }", c)
+		}
+		key.HeadComment = strings.Join(p.comments, "\n")
+		p.comments = nil
+		p.end(key)
+
+		if err := p.skip(); err != nil {
+			return nil, err
+		}
+		if p.peek() != ':' {
+			return nil, p.errorf("expecting : after key %q", key.Value)
+		}
+		p.advance(1)
+		if err := p.skip(); err != nil {
+			return nil, err
+		}
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+
+		// The last value of a repeated key wins, as with encoding/json
+		if existing := mappingValue(node, key.Value); existing != nil {
+			setMappingValue(node, key.Value, value)
+		} else {
+			node.Content = append(node.Content, key, value)
+		}
+		lastKey = key
+
+		if err := p.skip(); err != nil {
+			return nil, err
+		}
+		if p.peek() == '}' {
+			break
+		}
+		if p.peek() != ',' {
+			return nil, p.errorf("expecting , or } after the value of %q", key.Value)
+		}
+		p.advance(1)
+		if err := p.skip(); err != nil {
+			return nil, err
+		}
+		if p.peek() == '}' && p.dialect == jsonDialectStrict {
+			return nil, p.errorf("trailing commas are not allowed in strict JSON")
+		}
+	}
+
+	// Comments after the last entry are its foot comment
+	if lastKey != nil && len(p.comments) > 0 {
+		lastKey.FootComment = strings.Join(p.comments, "\n")
+		p.comments = nil
+	}
+	p.advance(1)
+	p.end(node)
+	return node, nil
+}
+
+func (p *jsonParser) array() (*yaml.Node, error) {
+	if p.depth >= jsonMaxDepth {
+		return nil, p.errorf("exceeded max nesting depth of %d", jsonMaxDepth)
+	}
+	p.depth++
+	defer func() { p.depth-- }()
+
+	node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle, Line: p.line, Column: p.column}
+	p.advance(1)
+	p.end(node)
+
+	for {
+		if err := p.skip(); err != nil {
+			return nil, err
+		}
+		if p.peek() == ']' {
+			break
+		}
+		comments := p.comments
+		p.comments = nil
+		item, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		item.HeadComment = strings.Join(comments, "\n")
+		node.Content = append(node.Content, item)
+
+		if err := p.skip(); err != nil {
+			return nil, err
+		}
+		if p.peek() == ']' {
+			break
+		}
+		if p.peek() != ',' {
+			return nil, p.errorf("expecting , or ] after array item")
+		}
+		p.advance(1)
+		if err := p.skip(); err != nil {
+			return nil, err
+		}
+		if p.peek() == ']' && p.dialect == jsonDialectStrict {
+			return nil, p.errorf("trailing commas are not allowed in strict JSON")
+		}
+	}
+	p.advance(1)
+	p.end(node)
+	return node, nil
+}
+
+// string returns the content of a quoted string, with escapes decoded.
+func (p *jsonParser) string() (string, error) {
+	quote := p.peek()
+	p.advance(1)
+	var b strings.Builder
+	for {
+		if p.pos >= len(p.src) {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.src[p.pos]
+		switch {
+		case c == quote:
+			p.advance(1)
+			return b.String(), nil
+		case c == '\n' && p.dialect != jsonDialectJSON5:
+			return "", p.errorf("unterminated string")
+		case c != '\\':
+			b.WriteByte(c)
+			p.advance(1)
+			continue
+		}
+
+		p.advance(1)
+		if p.pos >= len(p.src) {
+			return "", p.errorf("unterminated string")
+		}
+		e := p.src[p.pos]
+		p.advance(1)
+		switch e {
+		case '"', '\\', '/':
+			b.WriteByte(e)
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'u':
+			r, err := p.hexRune(4)
+			if err != nil {
+				return "", err
+			}
+			// Combine UTF-16 surrogate pairs
+			if utf16.IsSurrogate(r) && bytes.HasPrefix(p.src[p.pos:], []byte("\\u")) {
+				p.advance(2)
+				low, err := p.hexRune(4)
+				if err != nil {
+					return "", err
+				}
+				r = utf16.DecodeRune(r, low)
+			}
+			b.WriteRune(r)
+		default:
+			if p.dialect != jsonDialectJSON5 {
+				return "", p.errorf("invalid escape \\%c", e)
+			}
+			switch e {
+			case 'v':
+				b.WriteByte('\v')
+			case '0':
+				b.WriteByte(0)
+			case 'x':
+				r, err := p.hexRune(2)
+				if err != nil {
+					return "", err
+				}
+				b.WriteRune(r)
+			case '\n':
+				// An escaped line break continues the string on the next line
+			case '\r':
+				if p.peek() == '\n' {
+					p.advance(1)
+				}
+			default:
+				b.WriteByte(e)
+			}
+		}
+	}
+}
+
+func (p *jsonParser) hexRune(digits int) (rune, error) {
+	if p.pos+digits > len(p.src) {
+		return 0, p.errorf("invalid escape")
+	}
+	v, err := strconv.ParseUint(string(p.src[p.pos:p.pos+digits]), 16, 32)
+	if err != nil {
+		return 0, p.errorf("invalid escape")
+	}
+	p.advance(digits)
+	return rune(v), nil
+}
+
+// isJSON5Space reports whether the content starts with a non-ASCII space,
+// such as a non-breaking space or a line separator.
+func isJSON5Space(b []byte) bool {
+	r, _ := utf8.DecodeRune(b)
+	return unicode.IsSpace(r) || r == '\uFEFF'
+}
+
+func isJSON5IdentifierStart(c byte) bool {
+	return c == '_' || c == '$' || isASCIILetter(c) || c >= utf8.RuneSelf
+}
+
+func (p *jsonParser) identifier() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if !isJSON5IdentifierStart(c) && !(c >= '0' && c <= '9') {
+			break
+		}
+		p.advance(1)
+	}
+	return string(p.src[start:p.pos])
+}
+
+var (
+	jsonNumberRegex      = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+	json5NumberRegex     = regexp.MustCompile(`^[+-]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][+-]?[0-9]+)?$`)
+	json5HexNumberRegex  = regexp.MustCompile(`^[+-]?0[xX][0-9a-fA-F]+$`)
+	jsonIntegerRegex     = regexp.MustCompile(`^[+-]?[0-9]+$`)
+	jsonNumberCharacters = "0123456789abcdefABCDEFxX.+-"
+)
+
+// fitsInt64 returns true if an integer literal fits in an int64, or in an
+// uint64 if it is positive.
+func fitsInt64(s string) bool {
+	if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return true
+	}
+	_, err := strconv.ParseUint(strings.TrimPrefix(s, "+"), 10, 64)
+	return err == nil
+}
+
+func (p *jsonParser) number() (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Line: p.line, Column: p.column}
+	start := p.pos
+	for p.pos < len(p.src) && strings.IndexByte(jsonNumberCharacters, p.src[p.pos]) >= 0 {
+		p.advance(1)
+	}
+	// Infinity and NaN, with an optional sign
+	if p.dialect == jsonDialectJSON5 && isASCIILetter(p.peek()) {
+		p.identifier()
+	}
+	text := string(p.src[start:p.pos])
+
+	switch {
+	case jsonNumberRegex.MatchString(text):
+		node.Value = text
+	case p.dialect != jsonDialectJSON5:
+		return nil, fmt.Errorf("line %d column %d: invalid number %q", node.Line, node.Column, text)
+	case json5HexNumberRegex.MatchString(text):
+		sign, digits := "", strings.TrimLeft(text, "+-")
+		if strings.HasPrefix(text, "-") {
+			sign = "-"
+		}
+		v, err := strconv.ParseUint(digits[2:], 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d column %d: invalid number %q", node.Line, node.Column, text)
+		}
+		node.Value = sign + strconv.FormatUint(v, 10)
+	case json5NumberRegex.MatchString(text):
+		value := strings.TrimPrefix(text, "+")
+		if f, err := strconv.ParseFloat(value, 64); err == nil && !jsonIntegerRegex.MatchString(value) {
+			value = strconv.FormatFloat(f, 'g', -1, 64)
+		}
+		node.Value = value
+	case strings.TrimLeft(text, "+-") == "Infinity" || strings.TrimLeft(text, "+-") == "NaN":
+		// Infinity and NaN have no JSON representation, so are kept as strings
+		node.Tag = "!!str"
+		node.Value = strings.TrimPrefix(text, "+")
+		p.end(node)
+		return node, nil
+	default:
+		return nil, fmt.Errorf("line %d column %d: invalid number %q", node.Line, node.Column, text)
+	}
+
+	// Integers out of the range of 64 bits are decoded as floats, as by
+	// encoding/json
+	if jsonIntegerRegex.MatchString(node.Value) && fitsInt64(node.Value) {
+		node.Tag = "!!int"
+	}
+	p.end(node)
+	return node, nil
+}
@@ -15,14 +15,37 @@ func Plugin(ctx context.Context) *plugin.Plugin {
 		},
 		DefaultTransform: transform.FromCamel().NullIfZero(),
 		TableMap: map[string]*plugin.Table{
-			"ini_key_value":  tableINIKeyValue(ctx),
-			"ini_section":    tableINISection(ctx),
-			"json_file":      tableJSONFile(ctx),
-			"json_key_value": tableJSONKeyValue(ctx),
-			"toml_file":      tableTOMLFile(ctx),
-			"xml_file":       tableXMLFile(ctx),
-			"yml_file":       tableYMLFile(ctx),
-			"yml_key_value":  tableYMLKeyValue(ctx),
+			"apache_directive":        tableApacheDirective(ctx),
+			"config_diff":             tableConfigDiff(ctx),
+			"config_dependency":       tableConfigDependency(ctx),
+			"config_lockfile_package": tableConfigLockfilePackage(ctx),
+			"config_merged":           tableConfigMerged(ctx),
+			"cue_file":                tableCUEFile(ctx),
+			"cue_key_value":           tableCUEKeyValue(ctx),
+			"docker_compose_service":  tableDockerComposeService(ctx),
+			"dockerfile_instruction":  tableDockerfileInstruction(ctx),
+			"github_workflow_job":     tableGitHubWorkflowJob(ctx),
+			"github_workflow_step":    tableGitHubWorkflowStep(ctx),
+			"ini_key_value":           tableINIKeyValue(ctx),
+			"ini_section":             tableINISection(ctx),
+			"json_file":               tableJSONFile(ctx),
+			"json_key_value":          tableJSONKeyValue(ctx),
+			"jsonl_file":              tableJSONLFile(ctx),
+			"jsonnet_file":            tableJsonnetFile(ctx),
+			"jsonnet_key_value":       tableJsonnetKeyValue(ctx),
+			"kubernetes_manifest":     tableKubernetesManifest(ctx),
+			"maven_dependency":        tableMavenDependency(ctx),
+			"nginx_directive":         tableNginxDirective(ctx),
+			"plist_file":              tablePlistFile(ctx),
+			"plist_key_value":         tablePlistKeyValue(ctx),
+			"registry_value":          tableRegistryValue(ctx),
+			"ssh_config_key_value":    tableSSHConfigKeyValue(ctx),
+			"systemd_unit":            tableSystemdUnit(ctx),
+			"toml_file":               tableTOMLFile(ctx),
+			"xml_file":                tableXMLFile(ctx),
+			"xml_xpath":               tableXMLXPath(ctx),
+			"yml_file":                tableYMLFile(ctx),
+			"yml_key_value":           tableYMLKeyValue(ctx),
 		},
 	}
 	return p
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+func tableApacheDirective(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "apache_directive",
+		Description: "List the directives of Apache httpd config files, including the directives of included files.",
+		List: &plugin.ListConfig{
+			Hydrate: listApacheDirectives,
+			KeyColumns: plugin.KeyColumnSlice{
+				{
+					Name:    "path",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: directiveColumns(),
+	}
+}
+
+func listApacheDirectives(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// #1 - Path via qual
+	// If the path was requested through qualifier then match it exactly. Globs
+	// are not supported in this context since the output value for the column
+	// will never match the requested value.
+	//
+	// #2 - Path via glob paths in config
+	var paths []string
+	if d.EqualsQuals["path"] != nil {
+		paths = []string{d.EqualsQuals["path"].GetStringValue()}
+	} else {
+		var err error
+		paths, err = listApacheFiles(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range paths {
+		l := directiveLoader{
+			root:  apacheServerRoot(path),
+			parse: parseApacheConfig,
+			isInclude: func(directive string) bool {
+				return strings.EqualFold(directive, "Include") || strings.EqualFold(directive, "IncludeOptional")
+			},
+			rootDirective:      "ServerRoot",
+			includeDirectories: true,
+		}
+		nodes, err := l.load(path, 0)
+		if err != nil {
+			plugin.Logger(ctx).Error("apache_directive.listApacheDirectives", "parse_error", err, "path", path)
+			return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
+		}
+		var rows []directiveRow
+		flattenDirectives(nodes, "", &rows)
+		for _, row := range rows {
+			row.Path = path
+			d.StreamListItem(ctx, row)
+		}
+	}
+	return nil, nil
+}
+
+// apacheServerRoot returns the server root of a main config file when it has
+// no ServerRoot directive, i.e. the parent of its directory for the usual
+// conf/httpd.conf layout, or its directory otherwise.
+func apacheServerRoot(path string) string {
+	dir := filepath.Dir(path)
+	if filepath.Base(dir) == "conf" {
+		return filepath.Dir(dir)
+	}
+	return dir
+}
+
+// parseApacheConfig parses the directives of an Apache httpd config file.
+// Directives are one per line, where a trailing backslash continues the line,
+// and sections such as <VirtualHost *:443> are closed by </VirtualHost>.
+func parseApacheConfig(path string, content string) ([]*directiveNode, error) {
+	root := &directiveNode{Block: true}
+	stack := []*directiveNode{root}
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for n := 0; n < len(lines); n++ {
+		start := n
+		line := strings.TrimSpace(lines[n])
+		for strings.HasSuffix(line, "\\") && n+1 < len(lines) {
+			n++
+			line = strings.TrimSuffix(line, "\\") + " " + strings.TrimSpace(lines[n])
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parent := stack[len(stack)-1]
+		switch {
+		case strings.HasPrefix(line, "</"):
+			name := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "</"), ">"))
+			if len(stack) == 1 || !strings.EqualFold(name, parent.Directive) {
+				return nil, fmt.Errorf("unexpected </%s> on line %d", name, start+1)
+			}
+			stack = stack[:len(stack)-1]
+
+		case strings.HasPrefix(line, "<"):
+			if !strings.HasSuffix(line, ">") {
+				return nil, fmt.Errorf("section on line %d is not closed by >", start+1)
+			}
+			words := splitApacheArgs(line[1 : len(line)-1])
+			if len(words) == 0 {
+				return nil, fmt.Errorf("empty section on line %d", start+1)
+			}
+			node := &directiveNode{Directive: words[0], Args: words[1:], Block: true, SourcePath: path, Line: start + 1}
+			parent.Children = append(parent.Children, node)
+			stack = append(stack, node)
+
+		default:
+			words := splitApacheArgs(line)
+			parent.Children = append(parent.Children, &directiveNode{Directive: words[0], Args: words[1:], SourcePath: path, Line: start + 1})
+		}
+	}
+	if len(stack) > 1 {
+		open := stack[len(stack)-1]
+		return nil, fmt.Errorf("<%s> on line %d is not closed", open.Directive, open.Line)
+	}
+	return root.Children, nil
+}
+
+// splitApacheArgs splits a directive into words separated by whitespace,
+// where single or double quotes group words and are removed. A backslash
+// only escapes a quote, so Windows paths are kept as they are.
+func splitApacheArgs(s string) []string {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && (s[i+1] == '"' || s[i+1] == '\''):
+			i++
+			word.WriteByte(s[i])
+			inWord = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				word.WriteByte(c)
+			}
+		case (c == '"' || c == '\'') && !inWord:
+			quote = c
+			inWord = true
+		case c == ' ' || c == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
@@ -0,0 +1,578 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	"golang.org/x/mod/modfile"
+	"gopkg.in/yaml.v3"
+)
+
+func tableConfigDependency(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "config_dependency",
+		Description: "List the dependencies declared in package manifests, such as package.json, Cargo.toml, pyproject.toml, go.mod, Gemfile, requirements.txt and composer.json.",
+		List: &plugin.ListConfig{
+			Hydrate: listConfigDependency,
+			KeyColumns: plugin.KeyColumnSlice{
+				{
+					Name:    "source_path",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{Name: "ecosystem", Type: proto.ColumnType_STRING, Description: "The package ecosystem of the dependency, as named by OSV, i.e. npm, crates.io, PyPI, Go, RubyGems or Packagist."},
+			{Name: "name", Type: proto.ColumnType_STRING, Description: "The name of the package."},
+			{Name: "version_constraint", Type: proto.ColumnType_STRING, Description: "The version constraint of the dependency as declared, e.g. ^1.2.0 or >=2,<3. Null for dependencies without a version, such as Git or path dependencies."},
+			{Name: "dev", Type: proto.ColumnType_BOOL, Transform: transform.FromField("Dev"), Description: "True if the dependency is only needed for development or tests."},
+			{Name: "indirect", Type: proto.ColumnType_BOOL, Transform: transform.FromField("Indirect"), Description: "True for requirements of go.mod files marked as // indirect."},
+			{Name: "section", Type: proto.ColumnType_STRING, Description: "The section of the manifest declaring the dependency, e.g. devDependencies, dev-dependencies or require-dev."},
+			{Name: "source_path", Type: proto.ColumnType_STRING, Description: "Specifies the path of the manifest file."},
+			{Name: "line", Type: proto.ColumnType_INT, Description: "The line of the dependency in the manifest file."},
+		},
+	}
+}
+
+type configDependency struct {
+	SourcePath        string
+	Ecosystem         string
+	Name              string
+	VersionConstraint string
+	Dev               bool
+	Indirect          bool
+	Section           string
+	Line              int
+}
+
+func listConfigDependency(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// #1 - Path via qual
+	// If the path was requested through qualifier then match it exactly. Globs
+	// are not supported in this context since the output value for the column
+	// will never match the requested value.
+	//
+	// #2 - Path via glob paths in config
+	var paths []string
+	if d.EqualsQuals["source_path"] != nil {
+		paths = []string{d.EqualsQuals["source_path"].GetStringValue()}
+	} else {
+		var err error
+		paths, err = listDependencyFiles(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range paths {
+		// The manifests of installed packages are matched by globs such as
+		// **/package.json, but are not the dependencies of the project
+		if d.EqualsQuals["source_path"] == nil && isVendoredPath(path) {
+			plugin.Logger(ctx).Debug("config_dependency.listConfigDependency", "skip_vendored_file", path)
+			continue
+		}
+		parse := manifestParser(path)
+		if parse == nil {
+			if d.EqualsQuals["source_path"] != nil {
+				return nil, fmt.Errorf("unsupported manifest file %s", path)
+			}
+			plugin.Logger(ctx).Debug("config_dependency.listConfigDependency", "skip_unsupported_file", path)
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			plugin.Logger(ctx).Error("config_dependency.listConfigDependency", "read_error", err, "path", path)
+			return nil, fmt.Errorf("fail to read file %s: %v", path, err)
+		}
+		deps, err := parse(d, path, content)
+		if err != nil {
+			plugin.Logger(ctx).Error("config_dependency.listConfigDependency", "parse_error", err, "path", path)
+			return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
+		}
+		for _, dep := range deps {
+			dep.SourcePath = path
+			d.StreamListItem(ctx, dep)
+		}
+	}
+	return nil, nil
+}
+
+type manifestParserFunc func(d *plugin.QueryData, path string, content []byte) ([]configDependency, error)
+
+// manifestParser returns the parser of a manifest file based on its name, or
+// nil if the file is not a supported manifest.
+func manifestParser(path string) manifestParserFunc {
+	switch base := filepath.Base(path); {
+	case base == "package.json":
+		return parsePackageJSON
+	case base == "composer.json":
+		return parseComposerJSON
+	case base == "Cargo.toml":
+		return parseCargoTOML
+	case base == "pyproject.toml":
+		return parsePyprojectTOML
+	case base == "go.mod":
+		return parseGoMod
+	case base == "Gemfile" || base == "gems.rb":
+		return parseGemfile
+	case isRequirementsFile(path):
+		return parseRequirementsTxt
+	}
+	return nil
+}
+
+// isRequirementsFile returns true if a file is a pip requirements file, i.e. a
+// requirements*.txt file or a .txt file in a requirements directory, or a
+// pip-tools .in file and the .txt file compiled from it, such as dev.in and
+// dev.txt. Other .txt and .in files, such as LICENSE.txt or Makefile.in, are
+// not.
+func isRequirementsFile(path string) bool {
+	base := strings.ToLower(filepath.Base(path))
+	ext := filepath.Ext(base)
+	if ext != ".txt" && ext != ".in" {
+		return false
+	}
+	if strings.HasPrefix(base, "requirements") || strings.EqualFold(filepath.Base(filepath.Dir(path)), "requirements") {
+		return true
+	}
+	// pip-tools compiles each .in file to a .txt file of the same name
+	other := ".txt"
+	if ext == ".txt" {
+		other = ".in"
+	}
+	_, err := os.Stat(strings.TrimSuffix(path, filepath.Ext(path)) + other)
+	return err == nil
+}
+
+// isVendoredPath returns true if a path is in a directory of installed or
+// vendored packages, such as node_modules or vendor, whose manifests are not
+// the dependencies of the project.
+func isVendoredPath(path string) bool {
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(path)), "/") {
+		if dir == "node_modules" || dir == "vendor" || dir == "site-packages" {
+			return true
+		}
+	}
+	return false
+}
+
+// manifestRoot returns the root mapping of a parsed manifest, or nil if the
+// document is empty or not a mapping.
+func manifestRoot(doc *yaml.Node) *yaml.Node {
+	if doc == nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+	return doc.Content[0]
+}
+
+// manifestPath returns the node at a path of keys in a manifest, or nil if
+// any key is missing.
+func manifestPath(node *yaml.Node, keys ...string) *yaml.Node {
+	for _, key := range keys {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+		node = mappingValue(node, key)
+	}
+	return node
+}
+
+// mappingEntries calls fn for each key value pair of a mapping node.
+func mappingEntries(node *yaml.Node, fn func(key, value *yaml.Node)) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		fn(node.Content[i], node.Content[i+1])
+	}
+}
+
+// parsePackageJSON reads the dependencies of an npm package.json file.
+func parsePackageJSON(d *plugin.QueryData, path string, content []byte) ([]configDependency, error) {
+	doc, err := loadJSONNode(d, path, content)
+	if err != nil {
+		return nil, err
+	}
+	root := manifestRoot(doc)
+	var deps []configDependency
+	for _, section := range []string{"dependencies", "devDependencies", "peerDependencies", "optionalDependencies"} {
+		mappingEntries(manifestPath(root, section), func(key, value *yaml.Node) {
+			deps = append(deps, configDependency{
+				Ecosystem:         "npm",
+				Name:              key.Value,
+				VersionConstraint: value.Value,
+				Dev:               section == "devDependencies",
+				Section:           section,
+				Line:              key.Line,
+			})
+		})
+	}
+	return deps, nil
+}
+
+// parseComposerJSON reads the dependencies of a PHP composer.json file,
+// including platform requirements such as php and ext-json.
+func parseComposerJSON(d *plugin.QueryData, path string, content []byte) ([]configDependency, error) {
+	doc, err := loadJSONNode(d, path, content)
+	if err != nil {
+		return nil, err
+	}
+	root := manifestRoot(doc)
+	var deps []configDependency
+	for _, section := range []string{"require", "require-dev"} {
+		mappingEntries(manifestPath(root, section), func(key, value *yaml.Node) {
+			deps = append(deps, configDependency{
+				Ecosystem:         "Packagist",
+				Name:              key.Value,
+				VersionConstraint: value.Value,
+				Dev:               section == "require-dev",
+				Section:           section,
+				Line:              key.Line,
+			})
+		})
+	}
+	return deps, nil
+}
+
+// parseCargoTOML reads the dependencies of a Rust Cargo.toml file, including
+// platform specific and workspace dependencies. Renamed dependencies are
+// returned with the name of their package.
+func parseCargoTOML(d *plugin.QueryData, path string, content []byte) ([]configDependency, error) {
+	doc, err := parseTOMLNode(content)
+	if err != nil {
+		return nil, err
+	}
+	root := manifestRoot(doc)
+	var deps []configDependency
+	add := func(prefix string, table *yaml.Node) {
+		for _, section := range []string{"dependencies", "dev-dependencies", "build-dependencies"} {
+			mappingEntries(manifestPath(table, section), func(key, value *yaml.Node) {
+				dep := configDependency{
+					Ecosystem: "crates.io",
+					Name:      key.Value,
+					Dev:       section == "dev-dependencies",
+					Section:   prefix + section,
+					Line:      key.Line,
+				}
+				switch value.Kind {
+				case yaml.ScalarNode:
+					dep.VersionConstraint = value.Value
+				case yaml.MappingNode:
+					if v := manifestPath(value, "version"); v != nil {
+						dep.VersionConstraint = v.Value
+					}
+					if v := manifestPath(value, "package"); v != nil {
+						dep.Name = v.Value
+					}
+				}
+				deps = append(deps, dep)
+			})
+		}
+	}
+	add("", root)
+	add("workspace.", manifestPath(root, "workspace"))
+	mappingEntries(manifestPath(root, "target"), func(key, value *yaml.Node) {
+		add("target."+key.Value+".", value)
+	})
+	return deps, nil
+}
+
+// parsePyprojectTOML reads the dependencies of a Python pyproject.toml file,
+// as declared by PEP 621, PEP 735 dependency groups, or Poetry.
+func parsePyprojectTOML(d *plugin.QueryData, path string, content []byte) ([]configDependency, error) {
+	doc, err := parseTOMLNode(content)
+	if err != nil {
+		return nil, err
+	}
+	root := manifestRoot(doc)
+	var deps []configDependency
+	addRequirements := func(section string, dev bool, list *yaml.Node) {
+		if list == nil || list.Kind != yaml.SequenceNode {
+			return
+		}
+		for _, item := range list.Content {
+			// Dependency groups may include other groups with a table
+			if item.Kind != yaml.ScalarNode {
+				continue
+			}
+			if name, constraint, ok := parsePEP508(item.Value); ok {
+				deps = append(deps, configDependency{
+					Ecosystem:         "PyPI",
+					Name:              name,
+					VersionConstraint: constraint,
+					Dev:               dev,
+					Section:           section,
+					Line:              item.Line,
+				})
+			}
+		}
+	}
+	addPoetry := func(section string, dev bool, table *yaml.Node) {
+		mappingEntries(table, func(key, value *yaml.Node) {
+			// The Python version is not a package
+			if key.Value == "python" {
+				return
+			}
+			deps = append(deps, configDependency{
+				Ecosystem:         "PyPI",
+				Name:              key.Value,
+				VersionConstraint: poetryConstraint(value),
+				Dev:               dev,
+				Section:           section,
+				Line:              key.Line,
+			})
+		})
+	}
+
+	project := manifestPath(root, "project")
+	addRequirements("project.dependencies", false, manifestPath(project, "dependencies"))
+	mappingEntries(manifestPath(project, "optional-dependencies"), func(key, value *yaml.Node) {
+		addRequirements("project.optional-dependencies."+key.Value, false, value)
+	})
+	mappingEntries(manifestPath(root, "dependency-groups"), func(key, value *yaml.Node) {
+		addRequirements("dependency-groups."+key.Value, true, value)
+	})
+
+	poetry := manifestPath(root, "tool", "poetry")
+	addPoetry("tool.poetry.dependencies", false, manifestPath(poetry, "dependencies"))
+	addPoetry("tool.poetry.dev-dependencies", true, manifestPath(poetry, "dev-dependencies"))
+	mappingEntries(manifestPath(poetry, "group"), func(key, value *yaml.Node) {
+		addPoetry("tool.poetry.group."+key.Value+".dependencies", key.Value != "main", manifestPath(value, "dependencies"))
+	})
+	return deps, nil
+}
+
+// poetryConstraint returns the version constraint of a Poetry dependency,
+// which is either a version string, a table with a version, or an array of
+// tables with versions for different markers.
+func poetryConstraint(value *yaml.Node) string {
+	switch value.Kind {
+	case yaml.ScalarNode:
+		return value.Value
+	case yaml.MappingNode:
+		if v := manifestPath(value, "version"); v != nil {
+			return v.Value
+		}
+	case yaml.SequenceNode:
+		var versions []string
+		for _, item := range value.Content {
+			if v := poetryConstraint(item); v != "" {
+				versions = append(versions, v)
+			}
+		}
+		return strings.Join(versions, " || ")
+	}
+	return ""
+}
+
+var pep508Regex = regexp.MustCompile(`^([A-Za-z0-9](?:[A-Za-z0-9._-]*[A-Za-z0-9])?)\s*(?:\[[^\]]*\])?\s*(.*)$`)
+
+// parsePEP508 returns the name and version constraint of a PEP 508
+// requirement, such as requests[socks]>=2.8.1,<3; python_version < "3.8".
+// Markers are dropped, and requirements on a URL have no constraint.
+func parsePEP508(s string) (string, string, bool) {
+	if i := strings.Index(s, ";"); i >= 0 {
+		s = s[:i]
+	}
+	m := pep508Regex.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return "", "", false
+	}
+	constraint := strings.TrimSpace(m[2])
+	if strings.HasPrefix(constraint, "@") {
+		constraint = ""
+	}
+	constraint = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(constraint, "("), ")"))
+	return m[1], constraint, true
+}
+
+// requirementURLRegex matches requirements with a name on a URL, such as
+// pip @ https://github.com/pypa/pip/archive/22.0.2.zip.
+var requirementURLRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*\s*(\[[^\]]*\])?\s*@`)
+
+var requirementsDevRegex = regexp.MustCompile(`(^|[-_./])(dev|develop|development|test|tests|testing|lint|docs)([-_.]|$)`)
+
+// parseRequirementsTxt reads the requirements of a pip requirements file.
+// Options, such as -r and -e, and requirements on URLs or local paths are
+// skipped. The requirements are dev dependencies if the name of the file, or
+// of its directory, is about development or tests, e.g. requirements-dev.txt
+// or requirements/test.txt.
+func parseRequirementsTxt(d *plugin.QueryData, path string, content []byte) ([]configDependency, error) {
+	dev := requirementsDevRegex.MatchString(strings.ToLower(filepath.Base(path))) ||
+		requirementsDevRegex.MatchString(strings.ToLower(filepath.Base(filepath.Dir(path))))
+
+	var deps []configDependency
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	var line, start int
+	var text string
+	for scanner.Scan() {
+		line++
+		s := scanner.Text()
+		if text == "" {
+			start = line
+		}
+		// Lines ending with a backslash continue on the next line
+		if strings.HasSuffix(s, "\\") {
+			text += strings.TrimSuffix(s, "\\") + " "
+			continue
+		}
+		text += s
+		s, text = text, ""
+
+		// Comments start with a # preceded by whitespace
+		if i := strings.Index(s, " #"); i >= 0 {
+			s = s[:i]
+		}
+		if i := strings.Index(s, "\t#"); i >= 0 {
+			s = s[:i]
+		}
+		s = strings.TrimSpace(s)
+		if s == "" || strings.HasPrefix(s, "#") || strings.HasPrefix(s, "-") || strings.HasPrefix(s, ".") || strings.HasPrefix(s, "/") {
+			continue
+		}
+		if strings.Contains(s, "://") && !requirementURLRegex.MatchString(s) {
+			continue
+		}
+		// Drop per-requirement options, such as --hash
+		if i := strings.Index(s, " --"); i >= 0 {
+			s = s[:i]
+		}
+		if name, constraint, ok := parsePEP508(s); ok {
+			deps = append(deps, configDependency{
+				Ecosystem:         "PyPI",
+				Name:              name,
+				VersionConstraint: constraint,
+				Dev:               dev,
+				Section:           "requirements",
+				Line:              start,
+			})
+		}
+	}
+	return deps, scanner.Err()
+}
+
+// parseGoMod reads the requirements of a go.mod file. Replace and exclude
+// directives are not applied.
+func parseGoMod(d *plugin.QueryData, path string, content []byte) ([]configDependency, error) {
+	f, err := modfile.ParseLax(path, content, nil)
+	if err != nil {
+		return nil, err
+	}
+	var deps []configDependency
+	for _, r := range f.Require {
+		deps = append(deps, configDependency{
+			Ecosystem:         "Go",
+			Name:              r.Mod.Path,
+			VersionConstraint: r.Mod.Version,
+			Indirect:          r.Indirect,
+			Section:           "require",
+			Line:              r.Syntax.Start.Line,
+		})
+	}
+	return deps, nil
+}
+
+var (
+	gemRegex        = regexp.MustCompile(`^gem\s*\(?\s*["']([^"']+)["']\s*(.*)$`)
+	gemStringRegex  = regexp.MustCompile(`^\s*,\s*["']([^"']*)["']`)
+	gemVersionRegex = regexp.MustCompile(`^(~>|>=|<=|!=|>|<|=)?\s*\d`)
+	gemGroupRegex   = regexp.MustCompile(`^group\s*\(?\s*(.*?)\)?\s+do\b`)
+	gemInlineRegex  = regexp.MustCompile(`\bgroups?:\s*(\[[^\]]*\]|:\w+|["']\w+["'])|:groups?\s*=>\s*(\[[^\]]*\]|:\w+|["']\w+["'])`)
+	gemBlockRegex   = regexp.MustCompile(`\bdo\s*(\|[^|]*\|)?\s*$`)
+	gemEndRegex     = regexp.MustCompile(`^end\b`)
+	gemIfRegex      = regexp.MustCompile(`^(if|unless|case|begin|while|until)\b`)
+	gemNameRegex    = regexp.MustCompile(`:(\w+)|["'](\w+)["']`)
+)
+
+// parseGemfile reads the gems of a Bundler Gemfile. Gems in the development
+// or test groups, as a block or an option of the gem, are dev dependencies.
+// The file is not evaluated, so gems declared through Ruby code other than
+// gem statements are not found.
+func parseGemfile(d *plugin.QueryData, path string, content []byte) ([]configDependency, error) {
+	var deps []configDependency
+	// The groups of each open block, with nil for blocks other than groups
+	var blocks [][]string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	line := 0
+	for scanner.Scan() {
+		line++
+		s := strings.TrimSpace(scanner.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+
+		switch {
+		case gemEndRegex.MatchString(s):
+			if len(blocks) > 0 {
+				blocks = blocks[:len(blocks)-1]
+			}
+			continue
+		case gemGroupRegex.MatchString(s):
+			blocks = append(blocks, gemGroupNames(gemGroupRegex.FindStringSubmatch(s)[1]))
+			continue
+		case gemBlockRegex.MatchString(s) || gemIfRegex.MatchString(s):
+			blocks = append(blocks, nil)
+			if !gemRegex.MatchString(s) {
+				continue
+			}
+		}
+
+		m := gemRegex.FindStringSubmatch(s)
+		if m == nil {
+			continue
+		}
+		var versions []string
+		for rest := m[2]; ; {
+			v := gemStringRegex.FindStringSubmatch(rest)
+			if v == nil || !gemVersionRegex.MatchString(v[1]) {
+				break
+			}
+			versions = append(versions, v[1])
+			rest = rest[len(v[0]):]
+		}
+		groups := []string{}
+		for _, b := range blocks {
+			groups = append(groups, b...)
+		}
+		if g := gemInlineRegex.FindStringSubmatch(m[2]); g != nil {
+			groups = append(groups, gemGroupNames(g[1]+g[2])...)
+		}
+		section := "default"
+		dev := false
+		if len(groups) > 0 {
+			section = strings.Join(groups, ", ")
+		}
+		for _, g := range groups {
+			if g == "development" || g == "test" {
+				dev = true
+			}
+		}
+		deps = append(deps, configDependency{
+			Ecosystem:         "RubyGems",
+			Name:              m[1],
+			VersionConstraint: strings.Join(versions, ", "),
+			Dev:               dev,
+			Section:           section,
+			Line:              line,
+		})
+	}
+	return deps, scanner.Err()
+}
+
+// gemGroupNames returns the names of the groups given as symbols or strings,
+// e.g. :development, :test.
+func gemGroupNames(s string) []string {
+	var names []string
+	for _, m := range gemNameRegex.FindAllStringSubmatch(s, -1) {
+		names = append(names, m[1]+m[2])
+	}
+	return names
+}
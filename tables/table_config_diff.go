@@ -0,0 +1,229 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableConfigDiff(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "config_diff",
+		Description: "Compare the keys and values of two config files, or of a config file at two Git revisions.",
+		List: &plugin.ListConfig{
+			Hydrate: listConfigDiff,
+			KeyColumns: plugin.KeyColumnSlice{
+				{
+					Name:    "left_path",
+					Require: plugin.Required,
+				},
+				{
+					Name:    "right_path",
+					Require: plugin.Required,
+				},
+				{
+					Name:    "git_repo",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{Name: "left_path", Type: proto.ColumnType_STRING, Description: "The path of the left file, or a Git revision and path, e.g. HEAD~1:config/app.yml."},
+			{Name: "right_path", Type: proto.ColumnType_STRING, Description: "The path of the right file, or a Git revision and path, e.g. HEAD:config/app.yml."},
+			{Name: "git_repo", Type: proto.ColumnType_STRING, Transform: transform.FromQual("git_repo"), Description: "The directory of the Git repository that relative paths of Git revisions are read from, e.g. /src/app for HEAD~1:config/app.yml."},
+			{Name: "key_path", Type: proto.ColumnType_LTREE, Transform: transform.FromField("Key").Transform(keysToSnakeCase), Description: "Specifies full path of the changed key."},
+			{Name: "keys", Type: proto.ColumnType_JSON, Transform: transform.FromField("Key"), Description: "The array representation of path of the changed key."},
+			{Name: "change_type", Type: proto.ColumnType_STRING, Description: "The type of change, i.e. added, removed, modified or type_changed."},
+			{Name: "left_value", Type: proto.ColumnType_STRING, Description: "The value of the key in the left file."},
+			{Name: "right_value", Type: proto.ColumnType_STRING, Description: "The value of the key in the right file."},
+			{Name: "left_type", Type: proto.ColumnType_STRING, Description: "The data type of the value in the left file."},
+			{Name: "right_type", Type: proto.ColumnType_STRING, Description: "The data type of the value in the right file."},
+			{Name: "left_line", Type: proto.ColumnType_INT, Description: "The line number of the key in the left file."},
+			{Name: "right_line", Type: proto.ColumnType_INT, Description: "The line number of the key in the right file."},
+		},
+	}
+}
+
+type configDiffRow struct {
+	LeftPath   string
+	RightPath  string
+	Key        []string
+	ChangeType string
+	LeftValue  *string
+	RightValue *string
+	LeftType   string
+	RightType  string
+	LeftLine   int
+	RightLine  int
+}
+
+func listConfigDiff(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	leftPath := d.EqualsQuals["left_path"].GetStringValue()
+	rightPath := d.EqualsQuals["right_path"].GetStringValue()
+
+	gitRepo := d.EqualsQuals["git_repo"].GetStringValue()
+
+	left, err := readDiffSource(ctx, d, leftPath, gitRepo)
+	if err != nil {
+		plugin.Logger(ctx).Error("config_diff.listConfigDiff", "left_error", err, "path", leftPath)
+		return nil, fmt.Errorf("failed to parse file %s: %v", leftPath, err)
+	}
+	right, err := readDiffSource(ctx, d, rightPath, gitRepo)
+	if err != nil {
+		plugin.Logger(ctx).Error("config_diff.listConfigDiff", "right_error", err, "path", rightPath)
+		return nil, fmt.Errorf("failed to parse file %s: %v", rightPath, err)
+	}
+
+	rightByKey := map[string]Row{}
+	for _, r := range right {
+		rightByKey[strings.Join(r.Key, "\x00")] = r
+	}
+
+	// Removed and changed keys, in the order of the left file
+	seen := map[string]bool{}
+	for _, l := range left {
+		id := strings.Join(l.Key, "\x00")
+		seen[id] = true
+		row := configDiffRow{
+			LeftPath:  leftPath,
+			RightPath: rightPath,
+			Key:       l.Key,
+			LeftValue: diffValue(l.Value),
+			LeftType:  diffType(l.Tag),
+			LeftLine:  l.StartLine,
+		}
+		r, ok := rightByKey[id]
+		if !ok {
+			row.ChangeType = "removed"
+			d.StreamListItem(ctx, row)
+			continue
+		}
+		row.RightValue = diffValue(r.Value)
+		row.RightType = diffType(r.Tag)
+		row.RightLine = r.StartLine
+		switch {
+		case row.LeftType != row.RightType:
+			row.ChangeType = "type_changed"
+		case !equalDiffValues(row.LeftValue, row.RightValue):
+			row.ChangeType = "modified"
+		default:
+			continue
+		}
+		d.StreamListItem(ctx, row)
+	}
+
+	// Added keys, in the order of the right file
+	for _, r := range right {
+		if seen[strings.Join(r.Key, "\x00")] {
+			continue
+		}
+		d.StreamListItem(ctx, configDiffRow{
+			LeftPath:   leftPath,
+			RightPath:  rightPath,
+			Key:        r.Key,
+			ChangeType: "added",
+			RightValue: diffValue(r.Value),
+			RightType:  diffType(r.Tag),
+			RightLine:  r.StartLine,
+		})
+	}
+	return nil, nil
+}
+
+// readDiffSource reads and flattens one side of the diff. The source is read
+// from the local file system if it exists, otherwise a source in the form
+// <revision>:<path> is read from a Git repository. The plugin runs in a
+// directory chosen by Steampipe, so the repository is never taken from the
+// working directory: absolute paths are read from the repository containing
+// them, and relative paths from the gitRepo directory.
+func readDiffSource(ctx context.Context, d *plugin.QueryData, source string, gitRepo string) (Rows, error) {
+	if _, err := os.Stat(source); err == nil {
+		content, err := os.ReadFile(source)
+		if err != nil {
+			return nil, err
+		}
+		return flattenConfigContent(d, source, content)
+	} else if !strings.Contains(source, ":") {
+		return nil, err
+	}
+
+	// Guard against the revision being interpreted as a git option
+	if strings.HasPrefix(source, "-") {
+		return nil, fmt.Errorf("invalid git revision %q", source)
+	}
+	revision, path, _ := strings.Cut(source, ":")
+	var dir, object string
+	switch {
+	case filepath.IsAbs(path):
+		// A path starting with ./ is relative to the directory git runs in
+		dir, object = filepath.Dir(path), revision+":./"+filepath.Base(path)
+	case gitRepo != "":
+		dir, object = gitRepo, source
+	default:
+		return nil, fmt.Errorf("git revision %s has a relative path, use an absolute path or set git_repo", source)
+	}
+	content, err := exec.CommandContext(ctx, "git", "-C", dir, "show", object).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("git show %s: %s", source, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+	return flattenConfigContent(d, path, content)
+}
+
+// diffValue returns the string representation of a flattened value.
+func diffValue(value interface{}) *string {
+	var s string
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		s = v
+	case []string:
+		s = "[]"
+	case map[string]interface{}:
+		s = "{}"
+	default:
+		s = fmt.Sprint(v)
+	}
+	return &s
+}
+
+func equalDiffValues(left *string, right *string) bool {
+	if left == nil || right == nil {
+		return left == right
+	}
+	return *left == *right
+}
+
+// diffType returns the data type of a value from its YAML tag.
+func diffType(tag *string) string {
+	if tag == nil {
+		return ""
+	}
+	switch *tag {
+	case "!!str":
+		return "string"
+	case "!!int", "!!float":
+		return "number"
+	case "!!bool":
+		return "boolean"
+	case "!!null":
+		return "null"
+	case "!!map":
+		return "object"
+	case "!!seq":
+		return "array"
+	case "!!timestamp":
+		return "timestamp"
+	}
+	return strings.TrimPrefix(*tag, "!!")
+}
@@ -0,0 +1,653 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	"gopkg.in/yaml.v3"
+)
+
+func tableConfigLockfilePackage(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "config_lockfile_package",
+		Description: "List the resolved packages of lockfiles, such as package-lock.json, yarn.lock, pnpm-lock.yaml, Cargo.lock, poetry.lock and go.sum.",
+		List: &plugin.ListConfig{
+			Hydrate: listConfigLockfilePackage,
+			KeyColumns: plugin.KeyColumnSlice{
+				{
+					Name:    "path",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{Name: "ecosystem", Type: proto.ColumnType_STRING, Description: "The package ecosystem of the package, as named by OSV, i.e. npm, crates.io, PyPI or Go."},
+			{Name: "name", Type: proto.ColumnType_STRING, Description: "The name of the package."},
+			{Name: "version", Type: proto.ColumnType_STRING, Description: "The resolved version of the package."},
+			{Name: "integrity", Type: proto.ColumnType_STRING, Description: "The checksum of the package recorded in the lockfile, e.g. sha512-... for npm or h1:... for Go."},
+			{Name: "resolved_url", Type: proto.ColumnType_STRING, Description: "The URL the package was resolved from, e.g. the tarball URL for npm or the registry or Git source for Cargo."},
+			{Name: "direct", Type: proto.ColumnType_BOOL, Transform: transform.FromField("Direct"), Description: "True if the package is a direct dependency of the project, false if it is a transitive dependency. Null if it cannot be determined."},
+			{Name: "dev", Type: proto.ColumnType_BOOL, Transform: transform.FromField("Dev"), Description: "True if the package is only needed for development, as recorded by npm, pnpm and older Poetry lockfiles. Null if the lockfile does not record it."},
+			{Name: "path", Type: proto.ColumnType_STRING, Description: "Specifies the path of the lockfile."},
+		},
+	}
+}
+
+type lockfilePackage struct {
+	Path        string
+	Ecosystem   string
+	Name        string
+	Version     string
+	Integrity   string
+	ResolvedURL string
+	Direct      *bool
+	Dev         *bool
+}
+
+func listConfigLockfilePackage(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// #1 - Path via qual
+	// If the path was requested through qualifier then match it exactly. Globs
+	// are not supported in this context since the output value for the column
+	// will never match the requested value.
+	//
+	// #2 - Path via glob paths in config
+	var paths []string
+	if d.EqualsQuals["path"] != nil {
+		paths = []string{d.EqualsQuals["path"].GetStringValue()}
+	} else {
+		var err error
+		paths, err = listLockfiles(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range paths {
+		parse := lockfileParser(path)
+		if parse == nil {
+			if d.EqualsQuals["path"] != nil {
+				return nil, fmt.Errorf("unsupported lockfile %s", path)
+			}
+			plugin.Logger(ctx).Debug("config_lockfile_package.listConfigLockfilePackage", "skip_unsupported_file", path)
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			plugin.Logger(ctx).Error("config_lockfile_package.listConfigLockfilePackage", "read_error", err, "path", path)
+			return nil, fmt.Errorf("fail to read file %s: %v", path, err)
+		}
+		packages, err := parse(d, path, content)
+		if err != nil {
+			plugin.Logger(ctx).Error("config_lockfile_package.listConfigLockfilePackage", "parse_error", err, "path", path)
+			return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
+		}
+		for _, p := range packages {
+			p.Path = path
+			d.StreamListItem(ctx, p)
+		}
+	}
+	return nil, nil
+}
+
+type lockfileParserFunc func(d *plugin.QueryData, path string, content []byte) ([]lockfilePackage, error)
+
+// lockfileParser returns the parser of a lockfile based on its name, or nil if
+// the file is not a supported lockfile.
+func lockfileParser(path string) lockfileParserFunc {
+	switch filepath.Base(path) {
+	case "package-lock.json", "npm-shrinkwrap.json":
+		return parsePackageLock
+	case "yarn.lock":
+		return parseYarnLock
+	case "pnpm-lock.yaml":
+		return parsePnpmLock
+	case "Cargo.lock":
+		return parseCargoLock
+	case "poetry.lock":
+		return parsePoetryLock
+	case "go.sum":
+		return parseGoSum
+	}
+	return nil
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// siblingManifest returns the dependencies declared in the manifest with the
+// given name next to a lockfile, or false if there is no such manifest.
+func siblingManifest(d *plugin.QueryData, lockfile string, name string) ([]configDependency, bool) {
+	path := filepath.Join(filepath.Dir(lockfile), name)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	deps, err := manifestParser(path)(d, path, content)
+	if err != nil {
+		return nil, false
+	}
+	return deps, true
+}
+
+// npmPackageName returns the name of the package of a descriptor such as
+// lodash@^4.17.0, @babel/core@npm:^7.0.0 or a node_modules path.
+func npmPackageName(s string) string {
+	if i := strings.LastIndex(s, "node_modules/"); i >= 0 {
+		return s[i+len("node_modules/"):]
+	}
+	if i := strings.Index(s[min(1, len(s)):], "@"); i >= 0 {
+		return s[:i+1]
+	}
+	return s
+}
+
+type packageLock struct {
+	LockfileVersion int                           `json:"lockfileVersion"`
+	Packages        map[string]packageLockEntry   `json:"packages"`
+	Dependencies    map[string]packageLockV1Entry `json:"dependencies"`
+}
+
+type packageLockEntry struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Resolved             string            `json:"resolved"`
+	Integrity            string            `json:"integrity"`
+	Link                 bool              `json:"link"`
+	Dev                  bool              `json:"dev"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+type packageLockV1Entry struct {
+	Version      string                        `json:"version"`
+	Resolved     string                        `json:"resolved"`
+	Integrity    string                        `json:"integrity"`
+	Dev          bool                          `json:"dev"`
+	Dependencies map[string]packageLockV1Entry `json:"dependencies"`
+}
+
+// parsePackageLock reads the packages of an npm package-lock.json or
+// npm-shrinkwrap.json file. Lockfiles of version 2 and later record the direct
+// dependencies of the project, and for version 1 they are read from the
+// package.json file next to the lockfile. Workspace packages and links are
+// not included.
+func parsePackageLock(d *plugin.QueryData, path string, content []byte) ([]lockfilePackage, error) {
+	var lock packageLock
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+
+	var packages []lockfilePackage
+	if lock.Packages != nil {
+		root := lock.Packages[""]
+		direct := map[string]bool{}
+		for _, deps := range []map[string]string{root.Dependencies, root.DevDependencies, root.OptionalDependencies, root.PeerDependencies} {
+			for name := range deps {
+				direct[name] = true
+			}
+		}
+		for _, key := range sortedKeys(lock.Packages) {
+			entry := lock.Packages[key]
+			if !strings.Contains(key, "node_modules/") || entry.Link {
+				continue
+			}
+			name := npmPackageName(key)
+			if entry.Name != "" {
+				name = entry.Name
+			}
+			packages = append(packages, lockfilePackage{
+				Ecosystem:   "npm",
+				Name:        name,
+				Version:     entry.Version,
+				Integrity:   entry.Integrity,
+				ResolvedURL: entry.Resolved,
+				Direct:      boolPtr(strings.Count(key, "node_modules/") == 1 && strings.HasPrefix(key, "node_modules/") && direct[name]),
+				Dev:         boolPtr(entry.Dev),
+			})
+		}
+		return packages, nil
+	}
+
+	manifest, ok := siblingManifest(d, path, "package.json")
+	direct := map[string]bool{}
+	for _, dep := range manifest {
+		direct[dep.Name] = true
+	}
+	var walk func(deps map[string]packageLockV1Entry, top bool)
+	walk = func(deps map[string]packageLockV1Entry, top bool) {
+		for _, name := range sortedKeys(deps) {
+			entry := deps[name]
+			p := lockfilePackage{
+				Ecosystem:   "npm",
+				Name:        name,
+				Version:     entry.Version,
+				Integrity:   entry.Integrity,
+				ResolvedURL: entry.Resolved,
+				Dev:         boolPtr(entry.Dev),
+			}
+			if ok {
+				p.Direct = boolPtr(top && direct[name])
+			}
+			packages = append(packages, p)
+			walk(entry.Dependencies, false)
+		}
+	}
+	walk(lock.Dependencies, true)
+	return packages, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type yarnBerryEntry struct {
+	Version      string            `yaml:"version"`
+	Resolution   string            `yaml:"resolution"`
+	Checksum     string            `yaml:"checksum"`
+	Dependencies map[string]string `yaml:"dependencies"`
+}
+
+// parseYarnLock reads the packages of a yarn.lock file, in the format of Yarn
+// 1 or of Yarn 2 and later. Direct dependencies are those required by a
+// workspace, or for Yarn 1 by the package.json file next to the lockfile.
+func parseYarnLock(d *plugin.QueryData, path string, content []byte) ([]lockfilePackage, error) {
+	if bytes.Contains(content, []byte("__metadata:")) {
+		return parseYarnBerryLock(content)
+	}
+
+	type entry struct {
+		descriptors []string
+		fields      map[string]string
+	}
+	var entries []*entry
+	var current *entry
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			continue
+		case !strings.HasPrefix(line, " "):
+			current = &entry{fields: map[string]string{}}
+			for _, desc := range strings.Split(strings.TrimSuffix(trimmed, ":"), ",") {
+				current.descriptors = append(current.descriptors, strings.Trim(strings.TrimSpace(desc), `"`))
+			}
+			entries = append(entries, current)
+		case current != nil && strings.HasPrefix(line, "  ") && !strings.HasPrefix(line, "   "):
+			if key, value, ok := strings.Cut(trimmed, " "); ok {
+				current.fields[key] = strings.Trim(strings.TrimSpace(value), `"`)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	manifest, ok := siblingManifest(d, path, "package.json")
+	direct := map[string]bool{}
+	for _, dep := range manifest {
+		direct[dep.Name+"@"+dep.VersionConstraint] = true
+	}
+	var packages []lockfilePackage
+	for _, e := range entries {
+		if len(e.descriptors) == 0 || e.fields["version"] == "" {
+			continue
+		}
+		p := lockfilePackage{
+			Ecosystem:   "npm",
+			Name:        npmPackageName(e.descriptors[0]),
+			Version:     e.fields["version"],
+			Integrity:   e.fields["integrity"],
+			ResolvedURL: e.fields["resolved"],
+		}
+		if ok {
+			isDirect := false
+			for _, desc := range e.descriptors {
+				isDirect = isDirect || direct[desc]
+			}
+			p.Direct = boolPtr(isDirect)
+		}
+		packages = append(packages, p)
+	}
+	return packages, nil
+}
+
+func parseYarnBerryLock(content []byte) ([]lockfilePackage, error) {
+	var lock map[string]yarnBerryEntry
+	if err := yaml.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+
+	// The dependencies of workspaces are the direct dependencies
+	direct := map[string]bool{}
+	for _, entry := range lock {
+		if strings.Contains(entry.Resolution, "@workspace:") {
+			for name, rng := range entry.Dependencies {
+				direct[name+"@"+rng] = true
+			}
+		}
+	}
+
+	var packages []lockfilePackage
+	for _, key := range sortedKeys(lock) {
+		entry := lock[key]
+		if key == "__metadata" || entry.Resolution == "" || strings.Contains(entry.Resolution, "@workspace:") {
+			continue
+		}
+		isDirect := false
+		for _, desc := range strings.Split(key, ",") {
+			isDirect = isDirect || direct[strings.TrimSpace(desc)]
+		}
+		packages = append(packages, lockfilePackage{
+			Ecosystem: "npm",
+			Name:      npmPackageName(entry.Resolution),
+			Version:   entry.Version,
+			Integrity: entry.Checksum,
+			Direct:    boolPtr(isDirect),
+		})
+	}
+	return packages, nil
+}
+
+type pnpmLock struct {
+	Importers    map[string]pnpmImporter `yaml:"importers"`
+	pnpmImporter `yaml:",inline"`
+	Packages     map[string]pnpmPackage `yaml:"packages"`
+}
+
+// pnpmImporter holds the dependencies of a project. Versions are strings up to
+// lockfile version 5, and mappings with a specifier and a version after.
+type pnpmImporter struct {
+	Dependencies         map[string]interface{} `yaml:"dependencies"`
+	DevDependencies      map[string]interface{} `yaml:"devDependencies"`
+	OptionalDependencies map[string]interface{} `yaml:"optionalDependencies"`
+}
+
+type pnpmPackage struct {
+	Name       string `yaml:"name"`
+	Version    string `yaml:"version"`
+	Dev        *bool  `yaml:"dev"`
+	Resolution struct {
+		Integrity string `yaml:"integrity"`
+		Tarball   string `yaml:"tarball"`
+	} `yaml:"resolution"`
+}
+
+// parsePnpmLock reads the packages of a pnpm-lock.yaml file. Direct
+// dependencies are those of the importers, i.e. the projects of the
+// workspace.
+func parsePnpmLock(d *plugin.QueryData, path string, content []byte) ([]lockfilePackage, error) {
+	var lock pnpmLock
+	if err := yaml.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+
+	direct := map[string]bool{}
+	importers := []pnpmImporter{lock.pnpmImporter}
+	for _, importer := range lock.Importers {
+		importers = append(importers, importer)
+	}
+	for _, importer := range importers {
+		for _, deps := range []map[string]interface{}{importer.Dependencies, importer.DevDependencies, importer.OptionalDependencies} {
+			for name, v := range deps {
+				version, _ := v.(string)
+				if m, ok := v.(map[string]interface{}); ok {
+					version, _ = m["version"].(string)
+				}
+				direct[name+"@"+pnpmVersion(version)] = true
+			}
+		}
+	}
+
+	var packages []lockfilePackage
+	for _, key := range sortedKeys(lock.Packages) {
+		entry := lock.Packages[key]
+		name, version := parsePnpmKey(key)
+		if entry.Name != "" {
+			name = entry.Name
+		}
+		if entry.Version != "" {
+			version = entry.Version
+		}
+		packages = append(packages, lockfilePackage{
+			Ecosystem:   "npm",
+			Name:        name,
+			Version:     version,
+			Integrity:   entry.Resolution.Integrity,
+			ResolvedURL: entry.Resolution.Tarball,
+			Direct:      boolPtr(direct[name+"@"+version]),
+			Dev:         entry.Dev,
+		})
+	}
+	return packages, nil
+}
+
+// pnpmVersion removes the peer dependencies from a version, which are
+// suffixed in parentheses since lockfile version 6, and after an underscore
+// before.
+func pnpmVersion(version string) string {
+	if i := strings.Index(version, "("); i >= 0 {
+		version = version[:i]
+	}
+	if i := strings.Index(version, "_"); i >= 0 {
+		version = version[:i]
+	}
+	return version
+}
+
+// parsePnpmKey returns the name and version of a package key, e.g.
+// /@babel/core/7.0.0 up to lockfile version 5, /@babel/core@7.0.0 for version
+// 6, and @babel/core@7.0.0 since version 9.
+func parsePnpmKey(key string) (string, string) {
+	key = strings.TrimPrefix(key, "/")
+	scope := ""
+	if strings.HasPrefix(key, "@") {
+		if i := strings.Index(key, "/"); i >= 0 {
+			scope, key = key[:i+1], key[i+1:]
+		}
+	}
+	if i := strings.IndexAny(key, "@/"); i > 0 {
+		return scope + key[:i], pnpmVersion(key[i+1:])
+	}
+	return scope + key, ""
+}
+
+type cargoLock struct {
+	Package []struct {
+		Name         string   `toml:"name"`
+		Version      string   `toml:"version"`
+		Source       string   `toml:"source"`
+		Checksum     string   `toml:"checksum"`
+		Dependencies []string `toml:"dependencies"`
+	} `toml:"package"`
+	Metadata map[string]interface{} `toml:"metadata"`
+}
+
+// parseCargoLock reads the packages of a Rust Cargo.lock file. Packages
+// without a source are the crates of the workspace, which are not included,
+// and their dependencies are the direct dependencies.
+func parseCargoLock(d *plugin.QueryData, path string, content []byte) ([]lockfilePackage, error) {
+	var lock cargoLock
+	if err := toml.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+
+	// Dependencies are referred to by name, and by version and source if
+	// several packages have the same name
+	direct := map[string]bool{}
+	for _, p := range lock.Package {
+		if p.Source != "" {
+			continue
+		}
+		for _, dep := range p.Dependencies {
+			fields := strings.Fields(dep)
+			if len(fields) > 1 {
+				direct[fields[0]+" "+fields[1]] = true
+			} else {
+				direct[fields[0]] = true
+			}
+		}
+	}
+
+	var packages []lockfilePackage
+	for _, p := range lock.Package {
+		if p.Source == "" {
+			continue
+		}
+		checksum := p.Checksum
+		// Version 1 lockfiles keep the checksums in the metadata table
+		if checksum == "" {
+			checksum, _ = lock.Metadata[fmt.Sprintf("checksum %s %s (%s)", p.Name, p.Version, p.Source)].(string)
+		}
+		packages = append(packages, lockfilePackage{
+			Ecosystem:   "crates.io",
+			Name:        p.Name,
+			Version:     p.Version,
+			Integrity:   checksum,
+			ResolvedURL: p.Source,
+			Direct:      boolPtr(direct[p.Name] || direct[p.Name+" "+p.Version]),
+		})
+	}
+	return packages, nil
+}
+
+type poetryFile struct {
+	File string `toml:"file"`
+	Hash string `toml:"hash"`
+}
+
+type poetryLock struct {
+	Package []struct {
+		Name     string       `toml:"name"`
+		Version  string       `toml:"version"`
+		Category string       `toml:"category"`
+		Files    []poetryFile `toml:"files"`
+		Source   struct {
+			URL string `toml:"url"`
+		} `toml:"source"`
+	} `toml:"package"`
+	Metadata struct {
+		Files map[string][]poetryFile `toml:"files"`
+	} `toml:"metadata"`
+}
+
+var pythonNameRegex = regexp.MustCompile(`[-_.]+`)
+
+// normalizePythonName normalizes the name of a Python package as in PEP 503,
+// so names can be compared regardless of case and separators.
+func normalizePythonName(name string) string {
+	return pythonNameRegex.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+// parsePoetryLock reads the packages of a Python poetry.lock file. The
+// integrity is the hash of the source distribution, or of the first file if
+// there is none. Direct dependencies are read from the pyproject.toml file
+// next to the lockfile.
+func parsePoetryLock(d *plugin.QueryData, path string, content []byte) ([]lockfilePackage, error) {
+	var lock poetryLock
+	if err := toml.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+
+	manifest, ok := siblingManifest(d, path, "pyproject.toml")
+	direct := map[string]bool{}
+	for _, dep := range manifest {
+		direct[normalizePythonName(dep.Name)] = true
+	}
+
+	var packages []lockfilePackage
+	for _, p := range lock.Package {
+		files := p.Files
+		// Older lockfiles keep the files in the metadata table
+		if len(files) == 0 {
+			files = lock.Metadata.Files[p.Name]
+		}
+		var integrity string
+		for _, f := range files {
+			if integrity == "" || strings.HasSuffix(f.File, ".tar.gz") {
+				integrity = f.Hash
+			}
+			if strings.HasSuffix(f.File, ".tar.gz") {
+				break
+			}
+		}
+		pkg := lockfilePackage{
+			Ecosystem:   "PyPI",
+			Name:        p.Name,
+			Version:     p.Version,
+			Integrity:   integrity,
+			ResolvedURL: p.Source.URL,
+		}
+		if ok {
+			pkg.Direct = boolPtr(direct[normalizePythonName(p.Name)])
+		}
+		if p.Category != "" {
+			pkg.Dev = boolPtr(p.Category == "dev")
+		}
+		packages = append(packages, pkg)
+	}
+	return packages, nil
+}
+
+// parseGoSum reads the modules of a go.sum file. Only modules with a hash of
+// their content are included, since modules with only a go.mod hash are not
+// needed to build the main module. Direct dependencies are read from the
+// go.mod file next to the go.sum file.
+func parseGoSum(d *plugin.QueryData, path string, content []byte) ([]lockfilePackage, error) {
+	manifest, ok := siblingManifest(d, path, "go.mod")
+	direct := map[string]bool{}
+	for _, dep := range manifest {
+		if !dep.Indirect {
+			direct[dep.Name+"@"+dep.VersionConstraint] = true
+		}
+	}
+
+	var packages []lockfilePackage
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: malformed go.sum entry", line)
+		}
+		if strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		p := lockfilePackage{
+			Ecosystem: "Go",
+			Name:      fields[0],
+			Version:   fields[1],
+			Integrity: fields[2],
+		}
+		if ok {
+			p.Direct = boolPtr(direct[fields[0]+"@"+fields[1]])
+		}
+		packages = append(packages, p)
+	}
+	return packages, scanner.Err()
+}
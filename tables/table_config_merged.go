@@ -0,0 +1,218 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	"gopkg.in/yaml.v3"
+)
+
+func tableConfigMerged(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "config_merged",
+		Description: "List the effective key value pairs of layered config files, deep merged in the order defined by each merge_stack.",
+		List: &plugin.ListConfig{
+			Hydrate: listConfigMerged,
+			KeyColumns: plugin.KeyColumnSlice{
+				{
+					Name:    "stack",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{Name: "stack", Type: proto.ColumnType_STRING, Description: "The name of the merge_stack in the connection config."},
+			{Name: "key_path", Type: proto.ColumnType_LTREE, Transform: transform.FromField("Key").Transform(keysToSnakeCase), Description: "Specifies full path of a key in the merged config."},
+			{Name: "value", Type: proto.ColumnType_STRING, Description: "Specifies the effective value of the corresponding key."},
+			{Name: "keys", Type: proto.ColumnType_JSON, Transform: transform.FromField("Key"), Description: "The array representation of path of a key."},
+			{Name: "tag", Type: proto.ColumnType_STRING, Description: "Specifies the data type of the value."},
+			{Name: "source_path", Type: proto.ColumnType_STRING, Description: "The path of the layer the effective value was taken from."},
+			{Name: "source_line", Type: proto.ColumnType_INT, Description: "The line number of the value in the layer it was taken from."},
+		},
+	}
+}
+
+type configMergedRow struct {
+	Stack      string
+	Key        []string
+	Value      interface{}
+	Tag        *string
+	SourcePath string
+	SourceLine int
+}
+
+func listConfigMerged(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	cfg := GetConfig(d.Connection)
+	if len(cfg.MergeStacks) == 0 {
+		return nil, errors.New("merge_stack must be configured to query merged config")
+	}
+
+	for _, stack := range cfg.MergeStacks {
+		if d.EqualsQuals["stack"] != nil && d.EqualsQuals["stack"].GetStringValue() != stack.Name {
+			continue
+		}
+
+		m := configMerger{strategy: stack.ArrayMerge, key: stack.ArrayMergeKey, sources: map[*yaml.Node]string{}}
+		switch m.strategy {
+		case "":
+			m.strategy = "replace"
+		case "replace", "append":
+		case "by_key":
+			if m.key == "" {
+				return nil, fmt.Errorf("array_merge_key must be set for merge_stack %s when array_merge is by_key", stack.Name)
+			}
+		default:
+			return nil, fmt.Errorf("invalid array_merge %q for merge_stack %s, must be one of replace, append or by_key", m.strategy, stack.Name)
+		}
+
+		var merged *yaml.Node
+		for _, i := range stack.Paths {
+			// Layers are applied in the order of the paths, and the files
+			// matched by each path in lexical order
+			files, err := listPathsByFileType(ctx, d, []string{i})
+			if err != nil {
+				return nil, err
+			}
+			sort.Strings(files)
+
+			for _, path := range files {
+				content, err := os.ReadFile(path)
+				if err != nil {
+					plugin.Logger(ctx).Error("config_merged.listConfigMerged", "file_error", err, "path", path)
+					return nil, fmt.Errorf("failed to read file %s: %v", path, err)
+				}
+				root, err := parseConfigContent(d, path, content)
+				if err != nil {
+					plugin.Logger(ctx).Error("config_merged.listConfigMerged", "parse_error", err, "path", path)
+					return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
+				}
+
+				// Skip empty documents
+				if root.Kind == yaml.DocumentNode {
+					if len(root.Content) == 0 {
+						continue
+					}
+					root = root.Content[0]
+				}
+				m.recordSource(root, path)
+				merged = m.merge(merged, root)
+			}
+		}
+		if merged == nil {
+			continue
+		}
+
+		var rows Rows
+		treeToList(merged, []string{}, &rows, nil, nil, nil, false)
+		for _, r := range rows {
+			d.StreamListItem(ctx, configMergedRow{
+				Stack:      stack.Name,
+				Key:        r.Key,
+				Value:      r.Value,
+				Tag:        r.Tag,
+				SourcePath: m.sources[r.node],
+				SourceLine: r.StartLine,
+			})
+		}
+	}
+	return nil, nil
+}
+
+// configMerger deep merges YAML node trees, keeping track of the layer each
+// node came from. Nodes of the layers are never modified, collections are
+// copied when merged.
+type configMerger struct {
+	strategy string
+	key      string
+	sources  map[*yaml.Node]string
+}
+
+func (m *configMerger) recordSource(node *yaml.Node, path string) {
+	m.sources[node] = path
+	for _, i := range node.Content {
+		m.recordSource(i, path)
+	}
+}
+
+// merge returns the result of merging overlay on top of base. Mappings are
+// merged key by key, sequences according to the array merge strategy, and
+// any other value in overlay replaces the value in base.
+func (m *configMerger) merge(base *yaml.Node, overlay *yaml.Node) *yaml.Node {
+	if base == nil {
+		return overlay
+	}
+
+	switch {
+	case base.Kind == yaml.MappingNode && overlay.Kind == yaml.MappingNode:
+		result := m.copyNode(base, overlay)
+		index := map[string]int{}
+		for i := 0; i+1 < len(result.Content); i += 2 {
+			index[result.Content[i].Value] = i
+		}
+		for i := 0; i+1 < len(overlay.Content); i += 2 {
+			key, value := overlay.Content[i], overlay.Content[i+1]
+			if pos, ok := index[key.Value]; ok {
+				result.Content[pos+1] = m.merge(result.Content[pos+1], value)
+				continue
+			}
+			index[key.Value] = len(result.Content)
+			result.Content = append(result.Content, key, value)
+		}
+		return result
+
+	case base.Kind == yaml.SequenceNode && overlay.Kind == yaml.SequenceNode:
+		switch m.strategy {
+		case "append":
+			result := m.copyNode(base, overlay)
+			result.Content = append(result.Content, overlay.Content...)
+			return result
+		case "by_key":
+			result := m.copyNode(base, overlay)
+			for _, item := range overlay.Content {
+				id, ok := m.itemKey(item)
+				matched := false
+				for pos, existing := range result.Content {
+					if existingID, existingOk := m.itemKey(existing); ok && existingOk && existingID == id {
+						result.Content[pos] = m.merge(existing, item)
+						matched = true
+						break
+					}
+				}
+				if !matched {
+					result.Content = append(result.Content, item)
+				}
+			}
+			return result
+		}
+	}
+	return overlay
+}
+
+// copyNode returns a copy of base with its own content slice, attributed to
+// the layer of overlay.
+func (m *configMerger) copyNode(base *yaml.Node, overlay *yaml.Node) *yaml.Node {
+	result := *base
+	result.Content = append([]*yaml.Node{}, base.Content...)
+	m.sources[&result] = m.sources[overlay]
+	return &result
+}
+
+// itemKey returns the value of the array_merge_key of a sequence item, if the
+// item is a mapping with that key.
+func (m *configMerger) itemKey(item *yaml.Node) (string, bool) {
+	if item.Kind != yaml.MappingNode {
+		return "", false
+	}
+	for i := 0; i+1 < len(item.Content); i += 2 {
+		if item.Content[i].Value == m.key && item.Content[i+1].Kind == yaml.ScalarNode {
+			return item.Content[i+1].Value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	cueerrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/load"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+func tableCUEFile(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "cue_file",
+		Description: "Represents the JSON document produced by evaluating CUE files.",
+		List: &plugin.ListConfig{
+			Hydrate: listCUEFileWithPath,
+			KeyColumns: plugin.KeyColumnSlice{
+				{
+					Name:    "path",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{Name: "path", Type: proto.ColumnType_STRING, Description: "Specifies the path of the CUE file."},
+			{Name: "content", Type: proto.ColumnType_JSON, Description: "The JSON document produced by evaluating the file."},
+		},
+	}
+}
+
+type parseCUEContent struct {
+	Path    string
+	Content interface{}
+}
+
+func listCUEFileWithPath(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// #1 - Path via qual
+	// If the path was requested through qualifier then match it exactly. Globs
+	// are not supported in this context since the output value for the column
+	// will never match the requested value.
+	//
+	// #2 - Path via glob paths in config
+	var paths []string
+	if d.EqualsQuals["path"] != nil {
+		paths = []string{d.EqualsQuals["path"].GetStringValue()}
+	} else {
+		var err error
+		paths, err = listCUEFiles(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range paths {
+		output, err := evaluateCUE(d, path)
+		if err != nil {
+			plugin.Logger(ctx).Error("cue_file.listCUEFileWithPath", "evaluation_error", err, "path", path)
+			return nil, fmt.Errorf("failed to evaluate file %s: %v", path, err)
+		}
+		var result interface{}
+		if err := json.Unmarshal(output, &result); err != nil {
+			plugin.Logger(ctx).Error("cue_file.listCUEFileWithPath", "parse_error", err, "path", path)
+			return nil, fmt.Errorf("failed to unmarshal file content %s: %v", path, err)
+		}
+		d.StreamListItem(ctx, parseCUEContent{path, result})
+	}
+	return nil, nil
+}
+
+// cueTagRegex matches the @tag attributes of a CUE file, which tags are
+// injected into.
+var cueTagRegex = regexp.MustCompile(`@tag\(\s*([A-Za-z_$][A-Za-z0-9_$-]*)`)
+
+// evaluateCUE evaluates a CUE file on its own, as with cue export file.cue,
+// and returns the JSON output. All values must be concrete. Imports are
+// resolved from the CUE module of the file, i.e. the nearest parent directory
+// with a cue.mod directory, unless a module root is configured. Only the
+// configured tags used by the file are set, since CUE rejects unused tags.
+func evaluateCUE(d *plugin.QueryData, path string) ([]byte, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &load.Config{Dir: filepath.Dir(abs)}
+	if o := GetConfig(d.Connection).CUEOptions; o != nil {
+		cfg.ModuleRoot = o.ModuleRoot
+		content, err := os.ReadFile(abs)
+		if err != nil {
+			return nil, err
+		}
+		used := map[string]bool{}
+		for _, m := range cueTagRegex.FindAllStringSubmatch(string(content), -1) {
+			used[m[1]] = true
+		}
+		var names []string
+		for k := range o.Tags {
+			if used[k] {
+				names = append(names, k)
+			}
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			cfg.Tags = append(cfg.Tags, k+"="+o.Tags[k])
+		}
+	}
+
+	instances := load.Instances([]string{abs}, cfg)
+	if len(instances) != 1 {
+		return nil, errors.New("expected a single CUE instance")
+	}
+	if err := instances[0].Err; err != nil {
+		return nil, errors.New(strings.TrimSpace(cueerrors.Details(err, nil)))
+	}
+	value := cuecontext.New().BuildInstance(instances[0])
+	if err := value.Validate(cue.Concrete(true)); err != nil {
+		return nil, errors.New(strings.TrimSpace(cueerrors.Details(err, nil)))
+	}
+	output, err := value.MarshalJSON()
+	if err != nil {
+		return nil, errors.New(strings.TrimSpace(cueerrors.Details(err, nil)))
+	}
+	return output, nil
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+func tableCUEKeyValue(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "cue_key_value",
+		Description: "List all key value pairs of the JSON document produced by evaluating CUE files.",
+		List: &plugin.ListConfig{
+			Hydrate: listCUEKeyValue,
+			KeyColumns: plugin.KeyColumnSlice{
+				{
+					Name:    "path",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "include_containers",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: evaluatedKeyValueColumns("CUE"),
+	}
+}
+
+func listCUEKeyValue(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// #1 - Path via qual
+	// If the path was requested through qualifier then match it exactly. Globs
+	// are not supported in this context since the output value for the column
+	// will never match the requested value.
+	//
+	// #2 - Path via glob paths in config
+	var paths []string
+	if d.EqualsQuals["path"] != nil {
+		paths = []string{d.EqualsQuals["path"].GetStringValue()}
+	} else {
+		var err error
+		paths, err = listCUEFiles(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	includeContainers := d.EqualsQuals["include_containers"] != nil && d.EqualsQuals["include_containers"].GetBoolValue()
+	for _, path := range paths {
+		output, err := evaluateCUE(d, path)
+		if err != nil {
+			plugin.Logger(ctx).Error("cue_key_value.listCUEKeyValue", "evaluation_error", err, "path", path)
+			return nil, fmt.Errorf("failed to evaluate file %s: %v", path, err)
+		}
+		rows, err := flattenEvaluatedJSON(output, includeContainers)
+		if err != nil {
+			plugin.Logger(ctx).Error("cue_key_value.listCUEKeyValue", "parse_error", err, "path", path)
+			return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
+		}
+		for _, r := range rows {
+			r.Path = path
+			d.StreamListItem(ctx, r)
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,899 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	"gopkg.in/yaml.v3"
+)
+
+func tableDockerComposeService(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "docker_compose_service",
+		Description: "List the services of Docker Compose projects, after variable interpolation, extends and merging of override files.",
+		List: &plugin.ListConfig{
+			Hydrate: listDockerComposeServices,
+			KeyColumns: plugin.KeyColumnSlice{
+				{
+					Name:    "path",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{Name: "project", Type: proto.ColumnType_STRING, Description: "The name of the Compose project."},
+			{Name: "path", Type: proto.ColumnType_STRING, Description: "Specifies the path of the main Compose file of the project."},
+			{Name: "compose_files", Type: proto.ColumnType_JSON, Description: "The Compose files merged into the project, in order."},
+			{Name: "service", Type: proto.ColumnType_STRING, Description: "The name of the service."},
+			{Name: "image", Type: proto.ColumnType_STRING, Description: "The image the service runs."},
+			{Name: "build", Type: proto.ColumnType_JSON, Description: "The build configuration of the service."},
+			{Name: "command", Type: proto.ColumnType_JSON, Description: "The command of the service, overriding the default command of the image."},
+			{Name: "ports", Type: proto.ColumnType_JSON, Description: "The ports published by the service."},
+			{Name: "environment", Type: proto.ColumnType_JSON, Description: "The resolved environment variables of the service, including variables from env_file."},
+			{Name: "volumes", Type: proto.ColumnType_JSON, Description: "The volumes mounted by the service."},
+			{Name: "privileged", Type: proto.ColumnType_BOOL, Transform: transform.FromField("Privileged"), Description: "True if the service runs with elevated privileges."},
+			{Name: "networks", Type: proto.ColumnType_JSON, Description: "The networks the service is attached to."},
+			{Name: "depends_on", Type: proto.ColumnType_JSON, Description: "The services this service depends on, with the condition to wait for."},
+			{Name: "content", Type: proto.ColumnType_JSON, Description: "The full resolved definition of the service."},
+			{Name: "source_path", Type: proto.ColumnType_STRING, Description: "The path of the Compose file which first defines the service."},
+			{Name: "start_line", Type: proto.ColumnType_INT, Description: "The line number of the service in source_path."},
+		},
+	}
+}
+
+type dockerComposeService struct {
+	Project      string
+	Path         string
+	ComposeFiles []string
+	Service      string
+	Image        interface{}
+	Build        interface{}
+	Command      interface{}
+	Ports        interface{}
+	Environment  map[string]*string
+	Volumes      interface{}
+	Privileged   bool
+	Networks     interface{}
+	DependsOn    interface{}
+	Content      interface{}
+	SourcePath   string
+	StartLine    int
+}
+
+func listDockerComposeServices(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	cfg := GetConfig(d.Connection)
+
+	// #1 - Path via qual
+	// If the path was requested through qualifier then match it exactly. Globs
+	// are not supported in this context since the output value for the column
+	// will never match the requested value.
+	//
+	// #2 - Path via glob paths and projects in config
+	var projects []dockerComposeProject
+	if d.EqualsQuals["path"] != nil {
+		path := d.EqualsQuals["path"].GetStringValue()
+		projects = append(projects, dockerComposeProject{Files: withComposeOverrideFile(path)})
+	} else {
+		if cfg.DockerComposePaths != nil || len(cfg.DockerComposeProjects) == 0 {
+			paths, err := listDockerComposeFiles(ctx, d)
+			if err != nil {
+				return nil, err
+			}
+			for _, path := range paths {
+				// Override files are merged into the project of their main file
+				if isComposeOverrideFile(path) {
+					continue
+				}
+				projects = append(projects, dockerComposeProject{Files: withComposeOverrideFile(path)})
+			}
+		}
+		for _, project := range cfg.DockerComposeProjects {
+			var files []string
+			for _, i := range project.Files {
+				matches, err := listPathsByFileType(ctx, d, []string{i})
+				if err != nil {
+					return nil, err
+				}
+				sort.Strings(matches)
+				files = append(files, matches...)
+			}
+			if len(files) == 0 {
+				continue
+			}
+			project.Files = files
+			projects = append(projects, project)
+		}
+	}
+
+	for _, project := range projects {
+		services, err := loadComposeProject(project)
+		if err != nil {
+			plugin.Logger(ctx).Error("docker_compose_service.listDockerComposeServices", "parse_error", err, "path", project.Files[0])
+			return nil, fmt.Errorf("failed to parse file %s: %v", project.Files[0], err)
+		}
+		for _, service := range services {
+			d.StreamListItem(ctx, service)
+		}
+	}
+	return nil, nil
+}
+
+var composeDefaultFiles = map[string][]string{
+	"compose.yaml":        {"compose.override.yaml", "compose.override.yml"},
+	"compose.yml":         {"compose.override.yml", "compose.override.yaml"},
+	"docker-compose.yaml": {"docker-compose.override.yaml", "docker-compose.override.yml"},
+	"docker-compose.yml":  {"docker-compose.override.yml", "docker-compose.override.yaml"},
+}
+
+// withComposeOverrideFile returns the files of the project of a Compose file,
+// including the override file Compose applies by default to files with one of
+// the default names, e.g. compose.override.yaml for compose.yaml.
+func withComposeOverrideFile(path string) []string {
+	for _, i := range composeDefaultFiles[filepath.Base(path)] {
+		override := filepath.Join(filepath.Dir(path), i)
+		if _, err := os.Stat(override); err == nil {
+			return []string{path, override}
+		}
+	}
+	return []string{path}
+}
+
+// isComposeOverrideFile returns true if the file is the default override file
+// of a Compose file in the same directory.
+func isComposeOverrideFile(path string) bool {
+	for main, overrides := range composeDefaultFiles {
+		for _, i := range overrides {
+			if filepath.Base(path) != i {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(filepath.Dir(path), main)); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var composeProjectNameRegex = regexp.MustCompile(`[^a-z0-9_-]`)
+
+// loadComposeProject loads and merges the files of a Compose project, and
+// returns its services.
+func loadComposeProject(project dockerComposeProject) ([]dockerComposeService, error) {
+	dir := filepath.Dir(project.Files[0])
+
+	// Variables are taken from the environment, then from the .env file
+	envFile := project.EnvFile
+	if envFile == "" {
+		envFile = filepath.Join(dir, ".env")
+	}
+	dotEnv := map[string]string{}
+	if content, err := os.ReadFile(envFile); err == nil {
+		dotEnv, err = parseDotEnv(string(content), os.LookupEnv)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", envFile, err)
+		}
+	} else if project.EnvFile != "" {
+		return nil, err
+	}
+	l := &composeLoader{
+		files: map[string]*yaml.Node{},
+		lookup: func(name string) (string, bool) {
+			if value, ok := os.LookupEnv(name); ok {
+				return value, true
+			}
+			value, ok := dotEnv[name]
+			return value, ok
+		},
+	}
+
+	type source struct {
+		path string
+		line int
+	}
+	sources := map[string]source{}
+	var merged *yaml.Node
+	for _, path := range project.Files {
+		root, err := l.load(path)
+		if err != nil {
+			return nil, err
+		}
+		file := copyYAMLNode(root)
+		if services := mappingValue(file, "services"); services != nil && services.Kind == yaml.MappingNode {
+			services = copyYAMLNode(services)
+			setMappingValue(file, "services", services)
+			for i := 0; i+1 < len(services.Content); i += 2 {
+				name := services.Content[i].Value
+				if _, ok := sources[name]; !ok {
+					sources[name] = source{path: path, line: services.Content[i].Line}
+				}
+				services.Content[i+1], err = l.service(path, name, map[string]bool{})
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		merged = mergeComposeNode(merged, file, "")
+	}
+	if merged == nil || merged.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	name := project.Name
+	if name == "" {
+		if n := mappingValue(merged, "name"); n != nil && n.Kind == yaml.ScalarNode {
+			name = n.Value
+		} else {
+			name = composeProjectNameRegex.ReplaceAllString(strings.ToLower(filepath.Base(dir)), "")
+		}
+	}
+
+	var result []dockerComposeService
+	services := mappingValue(merged, "services")
+	if services == nil || services.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		service, node := services.Content[i].Value, services.Content[i+1]
+		if node.Kind != yaml.MappingNode {
+			continue
+		}
+		environment, err := l.environment(dir, node)
+		if err != nil {
+			return nil, fmt.Errorf("service %s: %v", service, err)
+		}
+		row := dockerComposeService{
+			Project:      name,
+			Path:         project.Files[0],
+			ComposeFiles: project.Files,
+			Service:      service,
+			Environment:  environment,
+			Content:      nodeToJSON(node),
+			SourcePath:   sources[service].path,
+			StartLine:    sources[service].line,
+		}
+		content, _ := row.Content.(map[string]interface{})
+		row.Image = content["image"]
+		row.Build = content["build"]
+		row.Command = content["command"]
+		row.Ports = content["ports"]
+		row.Volumes = content["volumes"]
+		row.Networks = content["networks"]
+		row.DependsOn = content["depends_on"]
+		row.Privileged, _ = content["privileged"].(bool)
+		result = append(result, row)
+	}
+	return result, nil
+}
+
+// composeLoader loads the files of a Compose project, including the files
+// referenced by extends, interpolating variables with lookup.
+type composeLoader struct {
+	files  map[string]*yaml.Node
+	lookup func(string) (string, bool)
+}
+
+// load returns the root mapping of a Compose file, with aliases resolved,
+// variables interpolated and the list forms of mappings normalized.
+func (l *composeLoader) load(path string) (*yaml.Node, error) {
+	if root, ok := l.files[path]; ok {
+		return root, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if len(doc.Content) > 0 && doc.Content[0].Kind == yaml.MappingNode {
+		if root, err = resolveYAMLAliases(doc.Content[0]); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	if err := interpolateComposeNode(root, l.lookup); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if services := mappingValue(root, "services"); services != nil && services.Kind == yaml.MappingNode {
+		for i := 1; i < len(services.Content); i += 2 {
+			normalizeComposeService(services.Content[i])
+		}
+	}
+	l.files[path] = root
+	return root, nil
+}
+
+// service returns the definition of a service in a file, merged on top of the
+// service it extends.
+func (l *composeLoader) service(path string, name string, visiting map[string]bool) (*yaml.Node, error) {
+	id := path + "\x00" + name
+	if visiting[id] {
+		return nil, fmt.Errorf("circular extends for service %s in %s", name, path)
+	}
+	visiting[id] = true
+
+	root, err := l.load(path)
+	if err != nil {
+		return nil, err
+	}
+	var service *yaml.Node
+	if services := mappingValue(root, "services"); services != nil && services.Kind == yaml.MappingNode {
+		service = mappingValue(services, name)
+	}
+	if service == nil {
+		return nil, fmt.Errorf("service %s not found in %s", name, path)
+	}
+	extends := mappingValue(service, "extends")
+	if extends == nil {
+		return service, nil
+	}
+
+	// extends is either the name of a service in the same file, or a mapping
+	// with the service and the file relative to this one
+	basePath, baseName := path, extends.Value
+	if extends.Kind == yaml.MappingNode {
+		baseName = ""
+		if n := mappingValue(extends, "service"); n != nil {
+			baseName = n.Value
+		}
+		if n := mappingValue(extends, "file"); n != nil && n.Value != "" {
+			basePath = n.Value
+			if !filepath.IsAbs(basePath) {
+				basePath = filepath.Join(filepath.Dir(path), basePath)
+			}
+		}
+	}
+	base, err := l.service(basePath, baseName, visiting)
+	if err != nil {
+		return nil, err
+	}
+	overlay := copyYAMLNode(service)
+	removeMappingKey(overlay, "extends")
+	return mergeComposeNode(base, overlay, ""), nil
+}
+
+// environment returns the resolved environment of a service, from its
+// env_file files and environment, where variables without a value are taken
+// from the project environment.
+func (l *composeLoader) environment(dir string, service *yaml.Node) (map[string]*string, error) {
+	environment := map[string]*string{}
+
+	if envFiles := mappingValue(service, "env_file"); envFiles != nil {
+		items := []*yaml.Node{envFiles}
+		if envFiles.Kind == yaml.SequenceNode {
+			items = envFiles.Content
+		}
+		for _, i := range items {
+			path, required := i.Value, true
+			if i.Kind == yaml.MappingNode {
+				path = ""
+				if n := mappingValue(i, "path"); n != nil {
+					path = n.Value
+				}
+				if n := mappingValue(i, "required"); n != nil {
+					required = n.Value != "false"
+				}
+			}
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				if !required && errors.Is(err, os.ErrNotExist) {
+					continue
+				}
+				return nil, err
+			}
+			values, err := parseDotEnv(string(content), l.lookup)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", path, err)
+			}
+			for k, v := range values {
+				value := v
+				environment[k] = &value
+			}
+		}
+	}
+
+	if env := mappingValue(service, "environment"); env != nil && env.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(env.Content); i += 2 {
+			key, value := env.Content[i].Value, env.Content[i+1]
+			if value.ShortTag() == "!!null" {
+				if v, ok := l.lookup(key); ok {
+					environment[key] = &v
+				} else {
+					environment[key] = nil
+				}
+				continue
+			}
+			v := value.Value
+			environment[key] = &v
+		}
+	}
+	return environment, nil
+}
+
+// normalizeComposeService converts the list forms of service attributes to
+// their mapping forms, so that they can be merged key by key.
+func normalizeComposeService(service *yaml.Node) {
+	if service.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(service.Content); i += 2 {
+		key, value := service.Content[i].Value, service.Content[i+1]
+		if value.Kind != yaml.SequenceNode {
+			if key == "build" && value.Kind == yaml.MappingNode {
+				if args := mappingValue(value, "args"); args != nil && args.Kind == yaml.SequenceNode {
+					*args = *composeListToMapping(args, "=")
+				}
+			}
+			continue
+		}
+		switch key {
+		case "environment", "labels", "annotations", "sysctls":
+			service.Content[i+1] = composeListToMapping(value, "=")
+		case "depends_on":
+			mapping := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: value.Line, Column: value.Column}
+			for _, item := range value.Content {
+				condition := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+					{Kind: yaml.ScalarNode, Tag: "!!str", Value: "condition"},
+					{Kind: yaml.ScalarNode, Tag: "!!str", Value: "service_started"},
+				}}
+				mapping.Content = append(mapping.Content, item, condition)
+			}
+			service.Content[i+1] = mapping
+		case "networks":
+			mapping := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: value.Line, Column: value.Column}
+			for _, item := range value.Content {
+				mapping.Content = append(mapping.Content, item, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"})
+			}
+			service.Content[i+1] = mapping
+		}
+	}
+}
+
+// composeListToMapping converts a list of KEY=VALUE items to a mapping, where
+// items without a separator map to null.
+func composeListToMapping(list *yaml.Node, sep string) *yaml.Node {
+	mapping := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: list.Line, Column: list.Column}
+	for _, item := range list.Content {
+		k, v, ok := strings.Cut(item.Value, sep)
+		value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v, Line: item.Line, Column: item.Column}
+		if !ok {
+			value = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Line: item.Line, Column: item.Column}
+		}
+		mapping.Content = append(mapping.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k, Line: item.Line, Column: item.Column},
+			value,
+		)
+	}
+	return mapping
+}
+
+// mergeComposeNode merges overlay on top of base following the Compose merge
+// rules. Mappings are merged key by key, and sequences are appended without
+// duplicates, except for commands which are replaced and mounts which are
+// merged by target. The !reset tag removes a value, and the !override tag
+// replaces it. Nil is returned for a removed value.
+func mergeComposeNode(base *yaml.Node, overlay *yaml.Node, field string) *yaml.Node {
+	switch overlay.Tag {
+	case "!reset":
+		return nil
+	case "!override":
+		result := *overlay
+		result.Tag = ""
+		result.Style &^= yaml.TaggedStyle
+		return &result
+	}
+	if base == nil {
+		return overlay
+	}
+
+	switch {
+	case base.Kind == yaml.MappingNode && overlay.Kind == yaml.MappingNode:
+		result := copyYAMLNode(base)
+		for i := 0; i+1 < len(overlay.Content); i += 2 {
+			key, value := overlay.Content[i], overlay.Content[i+1]
+			existing := mappingValue(result, key.Value)
+			merged := mergeComposeNode(existing, value, key.Value)
+			switch {
+			case merged == nil:
+				removeMappingKey(result, key.Value)
+			case existing == nil:
+				result.Content = append(result.Content, key, merged)
+			default:
+				setMappingValue(result, key.Value, merged)
+			}
+		}
+		return result
+
+	case base.Kind == yaml.SequenceNode && overlay.Kind == yaml.SequenceNode:
+		switch field {
+		case "command", "entrypoint", "test":
+			return overlay
+		case "volumes", "devices":
+			result := copyYAMLNode(base)
+			for _, item := range overlay.Content {
+				target := composeMountTarget(item)
+				replaced := false
+				for pos, existing := range result.Content {
+					if composeMountTarget(existing) == target {
+						result.Content[pos] = item
+						replaced = true
+						break
+					}
+				}
+				if !replaced {
+					result.Content = append(result.Content, item)
+				}
+			}
+			return result
+		default:
+			result := copyYAMLNode(base)
+			for _, item := range overlay.Content {
+				duplicate := false
+				for _, existing := range result.Content {
+					if item.Kind == yaml.ScalarNode && existing.Kind == yaml.ScalarNode && item.Value == existing.Value {
+						duplicate = true
+						break
+					}
+				}
+				if !duplicate {
+					result.Content = append(result.Content, item)
+				}
+			}
+			return result
+		}
+	}
+	return overlay
+}
+
+// composeMountTarget returns the path in the container of a volume or device,
+// in either the short SOURCE:TARGET[:MODE] syntax or the long syntax.
+func composeMountTarget(node *yaml.Node) string {
+	if node.Kind == yaml.MappingNode {
+		if target := mappingValue(node, "target"); target != nil {
+			return target.Value
+		}
+		return ""
+	}
+	parts := strings.Split(node.Value, ":")
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return parts[1]
+}
+
+// interpolateComposeNode interpolates the variables of all scalar values of
+// a tree in place. Plain scalars are re-typed from their interpolated value,
+// so that e.g. privileged: ${PRIVILEGED:-false} is a boolean.
+func interpolateComposeNode(node *yaml.Node, lookup func(string) (string, bool)) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		value, err := interpolateCompose(node.Value, lookup)
+		if err != nil {
+			return err
+		}
+		if value != node.Value && node.Style == 0 {
+			node.Tag = ""
+		}
+		node.Value = value
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := interpolateComposeNode(node.Content[i], lookup); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for _, i := range node.Content {
+			if err := interpolateComposeNode(i, lookup); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// interpolateCompose substitutes the variables in a value of a Compose file.
+// Both $VAR and ${VAR} are supported, along with the default (:- and -),
+// required (:? and ?) and alternative (:+ and +) forms, while $$ escapes a
+// literal $.
+func interpolateCompose(s string, lookup func(string) (string, bool)) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		switch next := s[i+1]; {
+		case next == '$':
+			b.WriteByte('$')
+			i++
+		case next == '{':
+			end := -1
+			depth := 0
+			for j := i + 1; j < len(s); j++ {
+				if s[j] == '{' {
+					depth++
+				} else if s[j] == '}' {
+					depth--
+					if depth == 0 {
+						end = j
+						break
+					}
+				}
+			}
+			if end < 0 {
+				return "", fmt.Errorf("invalid interpolation format for %q", s)
+			}
+			value, err := expandComposeVariable(s[i+2:end], lookup)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+			i = end
+		case next == '_' || isASCIILetter(next):
+			j := i + 1
+			for j < len(s) && isComposeNameChar(s[j]) {
+				j++
+			}
+			value, _ := lookup(s[i+1 : j])
+			b.WriteString(value)
+			i = j - 1
+		default:
+			b.WriteByte('$')
+		}
+	}
+	return b.String(), nil
+}
+
+// expandComposeVariable returns the value of the expression inside ${...}.
+func expandComposeVariable(expr string, lookup func(string) (string, bool)) (string, error) {
+	j := 0
+	for j < len(expr) && isComposeNameChar(expr[j]) {
+		j++
+	}
+	name, op := expr[:j], expr[j:]
+	if name == "" {
+		return "", fmt.Errorf("invalid interpolation format for ${%s}", expr)
+	}
+	value, set := lookup(name)
+	if op == "" {
+		return value, nil
+	}
+
+	// With a colon, an empty value is handled the same as an unset one
+	empty := !set
+	if strings.HasPrefix(op, ":") {
+		op = op[1:]
+		empty = !set || value == ""
+	}
+	if op == "" {
+		return "", fmt.Errorf("invalid interpolation format for ${%s}", expr)
+	}
+	arg := op[1:]
+	switch op[0] {
+	case '-':
+		if empty {
+			return interpolateCompose(arg, lookup)
+		}
+		return value, nil
+	case '?':
+		if empty {
+			message, err := interpolateCompose(arg, lookup)
+			if err != nil {
+				return "", err
+			}
+			return "", fmt.Errorf("required variable %s is missing a value: %s", name, message)
+		}
+		return value, nil
+	case '+':
+		if empty {
+			return "", nil
+		}
+		return interpolateCompose(arg, lookup)
+	}
+	return "", fmt.Errorf("invalid interpolation format for ${%s}", expr)
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isComposeNameChar(c byte) bool {
+	return c == '_' || isASCIILetter(c) || (c >= '0' && c <= '9')
+}
+
+var dotEnvUnescaper = strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, `"`, `\\`, `\`)
+
+// parseDotEnv parses the KEY=VALUE lines of a .env or env_file file. Single
+// quoted values are literal, while double quoted and unquoted values are
+// interpolated with earlier variables of the file, then with lookup. A key
+// without a value is taken from lookup, if set.
+func parseDotEnv(content string, lookup func(string) (string, bool)) (map[string]string, error) {
+	values := map[string]string{}
+	local := func(name string) (string, bool) {
+		if value, ok := values[name]; ok {
+			return value, true
+		}
+		return lookup(name)
+	}
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for n := 0; n < len(lines); n++ {
+		line := strings.TrimSpace(lines[n])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok {
+			if v, set := lookup(key); set {
+				values[key] = v
+			}
+			continue
+		}
+		value = strings.TrimLeft(value, " \t")
+
+		if value != "" && (value[0] == '"' || value[0] == '\'') {
+			quote := value[0]
+			// Quoted values may span several lines
+			end := closingQuote(value, quote)
+			for end < 0 && n+1 < len(lines) {
+				n++
+				value += "\n" + lines[n]
+				end = closingQuote(value, quote)
+			}
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted value for %s", key)
+			}
+			value = value[1:end]
+			if quote == '\'' {
+				values[key] = value
+				continue
+			}
+			value = dotEnvUnescaper.Replace(value)
+		} else {
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = value[:i]
+			}
+			value = strings.TrimSpace(value)
+		}
+		interpolated, err := interpolateCompose(value, local)
+		if err != nil {
+			return nil, err
+		}
+		values[key] = interpolated
+	}
+	return values, nil
+}
+
+// closingQuote returns the index of the quote closing the value starting with
+// an opening quote, or -1 if the value is not terminated.
+func closingQuote(value string, quote byte) int {
+	for i := 1; i < len(value); i++ {
+		if value[i] == '\\' && quote == '"' {
+			i++
+			continue
+		}
+		if value[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+// yamlMaxResolvedNodes bounds the number of nodes of a tree with aliases
+// resolved, since aliases of aliases expand exponentially.
+const yamlMaxResolvedNodes = 1 << 20
+
+// resolveYAMLAliases returns a copy of a tree with aliases replaced by copies
+// of the nodes they refer to, and merge keys (<<) expanded into the mappings
+// they are used in. Unlike decoding with yaml.v3, walking the nodes is not
+// protected against excessive aliasing, so the expansion is bounded, and
+// aliases referring to their own anchor are rejected.
+func resolveYAMLAliases(node *yaml.Node) (*yaml.Node, error) {
+	r := &yamlAliasResolver{visiting: map[*yaml.Node]bool{}}
+	return r.resolve(node)
+}
+
+type yamlAliasResolver struct {
+	nodes    int
+	visiting map[*yaml.Node]bool
+}
+
+func (r *yamlAliasResolver) resolve(node *yaml.Node) (*yaml.Node, error) {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		if r.visiting[node.Alias] {
+			return nil, fmt.Errorf("line %d: alias *%s refers to itself", node.Line, node.Value)
+		}
+		r.visiting[node.Alias] = true
+		defer delete(r.visiting, node.Alias)
+		return r.resolve(node.Alias)
+	}
+	r.nodes++
+	if r.nodes > yamlMaxResolvedNodes {
+		return nil, fmt.Errorf("document expands to more than %d nodes through aliases", yamlMaxResolvedNodes)
+	}
+	result := *node
+	result.Content = nil
+	if node.Kind != yaml.MappingNode {
+		for _, i := range node.Content {
+			child, err := r.resolve(i)
+			if err != nil {
+				return nil, err
+			}
+			result.Content = append(result.Content, child)
+		}
+		return &result, nil
+	}
+
+	var merged []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		value, err := r.resolve(node.Content[i+1])
+		if err != nil {
+			return nil, err
+		}
+		if key.ShortTag() == "!!merge" {
+			if value.Kind == yaml.SequenceNode {
+				merged = append(merged, value.Content...)
+			} else {
+				merged = append(merged, value)
+			}
+			continue
+		}
+		k := *key
+		result.Content = append(result.Content, &k, value)
+	}
+
+	// Explicit keys take precedence over merged ones, and earlier merged
+	// mappings over later ones
+	for _, m := range merged {
+		for i := 0; i+1 < len(m.Content); i += 2 {
+			if mappingValue(&result, m.Content[i].Value) == nil {
+				result.Content = append(result.Content, m.Content[i], m.Content[i+1])
+			}
+		}
+	}
+	return &result, nil
+}
+
+// copyYAMLNode returns a shallow copy of a node with its own content slice.
+func copyYAMLNode(node *yaml.Node) *yaml.Node {
+	result := *node
+	result.Content = append([]*yaml.Node{}, node.Content...)
+	return &result
+}
+
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+}
+
+func removeMappingKey(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
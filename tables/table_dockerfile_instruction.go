@@ -0,0 +1,349 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableDockerfileInstruction(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "dockerfile_instruction",
+		Description: "List the instructions of Dockerfiles, one row per instruction.",
+		List: &plugin.ListConfig{
+			Hydrate: listDockerfileInstructions,
+			KeyColumns: plugin.KeyColumnSlice{
+				{
+					Name:    "path",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{Name: "path", Type: proto.ColumnType_STRING, Description: "Specifies the path of the Dockerfile."},
+			{Name: "stage", Type: proto.ColumnType_STRING, Description: "The name of the build stage of the instruction, as set with FROM ... AS name."},
+			{Name: "stage_index", Type: proto.ColumnType_INT, Transform: transform.FromField("StageIndex"), Description: "The zero-based index of the build stage of the instruction. Not set for ARG instructions before the first FROM."},
+			{Name: "instruction", Type: proto.ColumnType_STRING, Description: "The instruction in upper case, e.g. RUN."},
+			{Name: "flags", Type: proto.ColumnType_JSON, Description: "The flags of the instruction, e.g. --from=build."},
+			{Name: "arguments", Type: proto.ColumnType_JSON, Description: "The arguments of the instruction. Exec form arguments are returned as they are, shell form commands as a single argument, and other arguments split into words."},
+			{Name: "heredocs", Type: proto.ColumnType_JSON, Description: "The here-documents of the instruction, with their name and content."},
+			{Name: "original", Type: proto.ColumnType_STRING, Description: "The original text of the instruction, including continuation lines and here-documents."},
+			{Name: "base_image", Type: proto.ColumnType_STRING, Description: "The image or stage a FROM instruction is based on, with global ARG defaults substituted."},
+			{Name: "base_image_tag", Type: proto.ColumnType_STRING, Description: "The tag of the base image of a FROM instruction."},
+			{Name: "base_image_digest", Type: proto.ColumnType_STRING, Description: "The digest of the base image of a FROM instruction."},
+			{Name: "start_line", Type: proto.ColumnType_INT, Description: "The line number where the instruction starts."},
+			{Name: "end_line", Type: proto.ColumnType_INT, Description: "The line number where the instruction ends."},
+		},
+	}
+}
+
+type dockerfileInstruction struct {
+	Path            string
+	Stage           string
+	StageIndex      *int
+	Instruction     string
+	Flags           []string
+	Arguments       []string
+	Heredocs        []dockerfileHeredoc
+	Original        string
+	BaseImage       string
+	BaseImageTag    string
+	BaseImageDigest string
+	StartLine       int
+	EndLine         int
+}
+
+type dockerfileHeredoc struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+func listDockerfileInstructions(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// #1 - Path via qual
+	// If the path was requested through qualifier then match it exactly. Globs
+	// are not supported in this context since the output value for the column
+	// will never match the requested value.
+	//
+	// #2 - Path via glob paths in config
+	var paths []string
+	if d.EqualsQuals["path"] != nil {
+		paths = []string{d.EqualsQuals["path"].GetStringValue()}
+	} else {
+		var err error
+		paths, err = listDockerfileFiles(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			plugin.Logger(ctx).Error("dockerfile_instruction.listDockerfileInstructions", "file_error", err, "path", path)
+			return nil, fmt.Errorf("failed to read file %s: %v", path, err)
+		}
+		instructions, err := parseDockerfile(string(content))
+		if err != nil {
+			plugin.Logger(ctx).Error("dockerfile_instruction.listDockerfileInstructions", "parse_error", err, "path", path)
+			return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
+		}
+		for _, i := range instructions {
+			i.Path = path
+			d.StreamListItem(ctx, i)
+		}
+	}
+	return nil, nil
+}
+
+var (
+	dockerfileDirectiveRegex = regexp.MustCompile(`^#\s*([a-zA-Z][a-zA-Z0-9]*)\s*=\s*(.+?)\s*$`)
+	dockerfileHeredocRegex   = regexp.MustCompile(`^<<(-?)(?:"([^"]+)"|'([^']+)'|([A-Za-z_][A-Za-z0-9_.-]*))`)
+)
+
+// dockerfileHeredocMarkers returns the matches of the here-document markers
+// of an instruction, e.g. <<EOF or <<-"EOF". Quoted strings are skipped, so
+// RUN echo "a <<EOF b" has none, as are here-strings such as <<<. The escape
+// character escapes the next character, as in splitDockerfileWords.
+func dockerfileHeredocMarkers(s string, escape byte) [][]string {
+	var markers [][]string
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == escape && quote != '\'':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case strings.HasPrefix(s[i:], "<<<"):
+			i += 2
+		case strings.HasPrefix(s[i:], "<<"):
+			if m := dockerfileHeredocRegex.FindStringSubmatch(s[i:]); m != nil {
+				markers = append(markers, m)
+				i += len(m[0]) - 1
+			}
+		}
+	}
+	return markers
+}
+
+// parseDockerfile splits a Dockerfile into its instructions. Lines ending with
+// the escape character continue on the next line, skipping comments and empty
+// lines, and the here-documents of RUN, COPY and ADD instructions are read up
+// to their terminating line.
+func parseDockerfile(content string) ([]dockerfileInstruction, error) {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	// Parser directives are only allowed before any other line
+	escape := "\\"
+	for _, line := range lines {
+		m := dockerfileDirectiveRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			break
+		}
+		if strings.EqualFold(m[1], "escape") {
+			if m[2] != "\\" && m[2] != "`" {
+				return nil, fmt.Errorf("invalid escape character %q, must be \\ or `", m[2])
+			}
+			escape = m[2]
+		}
+	}
+
+	var instructions []dockerfileInstruction
+	globalArgs := map[string]string{}
+	stages := map[string]bool{}
+	stageIndex := -1
+	stage := ""
+
+	for n := 0; n < len(lines); {
+		trimmed := strings.TrimSpace(lines[n])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			n++
+			continue
+		}
+
+		// Join continuation lines into a single logical line
+		start := n
+		var logical strings.Builder
+		for n < len(lines) {
+			line := strings.TrimRight(lines[n], " \t")
+			n++
+			if !strings.HasSuffix(line, escape) {
+				logical.WriteString(line)
+				break
+			}
+			logical.WriteString(strings.TrimSuffix(line, escape))
+			for n < len(lines) {
+				if t := strings.TrimSpace(lines[n]); t != "" && !strings.HasPrefix(t, "#") {
+					break
+				}
+				n++
+			}
+		}
+
+		keyword, rest, _ := strings.Cut(strings.TrimSpace(logical.String()), " ")
+		if i := strings.IndexAny(keyword, "\t"); i >= 0 {
+			keyword, rest = keyword[:i], keyword[i+1:]+" "+rest
+		}
+		instruction := dockerfileInstruction{
+			Instruction: strings.ToUpper(keyword),
+			StartLine:   start + 1,
+		}
+		rest = strings.TrimSpace(rest)
+
+		// Flags come before the arguments, e.g. COPY --from=build
+		for strings.HasPrefix(rest, "--") {
+			flag, remaining, _ := strings.Cut(rest, " ")
+			instruction.Flags = append(instruction.Flags, flag)
+			rest = strings.TrimSpace(remaining)
+		}
+
+		var args []string
+		execForm := false
+		if strings.HasPrefix(rest, "[") {
+			execForm = json.Unmarshal([]byte(rest), &args) == nil
+		}
+		switch {
+		case execForm:
+		case instruction.Instruction == "RUN" || instruction.Instruction == "CMD" || instruction.Instruction == "ENTRYPOINT" || instruction.Instruction == "SHELL" || instruction.Instruction == "ONBUILD":
+			args = []string{rest}
+		case instruction.Instruction == "HEALTHCHECK":
+			first, command, _ := strings.Cut(rest, " ")
+			args = []string{first}
+			if command = strings.TrimSpace(command); command != "" {
+				args = append(args, command)
+			}
+		default:
+			args = splitDockerfileWords(rest, escape[0])
+		}
+		instruction.Arguments = args
+
+		// Here-documents start on the line after the instruction
+		if !execForm && (instruction.Instruction == "RUN" || instruction.Instruction == "COPY" || instruction.Instruction == "ADD") {
+			for _, m := range dockerfileHeredocMarkers(rest, escape[0]) {
+				name := m[2] + m[3] + m[4]
+				var body []string
+				for n < len(lines) {
+					line := lines[n]
+					n++
+					if m[1] == "-" {
+						line = strings.TrimLeft(line, "\t")
+					}
+					if line == name {
+						break
+					}
+					body = append(body, line)
+				}
+				content := strings.Join(body, "\n")
+				if len(body) > 0 {
+					content += "\n"
+				}
+				instruction.Heredocs = append(instruction.Heredocs, dockerfileHeredoc{Name: name, Content: content})
+			}
+		}
+		instruction.EndLine = n
+		for instruction.EndLine > start+1 && strings.TrimSpace(lines[instruction.EndLine-1]) == "" {
+			instruction.EndLine--
+		}
+		instruction.Original = strings.Join(lines[start:instruction.EndLine], "\n")
+
+		switch instruction.Instruction {
+		case "ARG":
+			// ARGs before the first FROM can be used in FROM instructions
+			if stageIndex < 0 {
+				for _, i := range args {
+					name, value, _ := strings.Cut(i, "=")
+					globalArgs[name] = value
+				}
+			}
+		case "FROM":
+			stageIndex++
+			stage = ""
+			if len(args) >= 3 && strings.EqualFold(args[1], "AS") {
+				stage = args[2]
+			}
+			if len(args) > 0 {
+				image, _ := interpolateCompose(args[0], func(name string) (string, bool) {
+					value, ok := globalArgs[name]
+					return value, ok
+				})
+				instruction.BaseImage = image
+				if !stages[strings.ToLower(image)] {
+					instruction.BaseImage, instruction.BaseImageTag, instruction.BaseImageDigest = splitImageReference(image)
+				}
+			}
+			if stage != "" {
+				stages[strings.ToLower(stage)] = true
+			}
+		}
+		if stageIndex >= 0 {
+			index := stageIndex
+			instruction.StageIndex = &index
+			instruction.Stage = stage
+		}
+		instructions = append(instructions, instruction)
+	}
+	return instructions, nil
+}
+
+// splitImageReference splits an image reference such as
+// registry:5000/repo/name:tag@sha256:digest into its name, tag and digest.
+func splitImageReference(image string) (string, string, string) {
+	name, digest, _ := strings.Cut(image, "@")
+	tag := ""
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+	return name, tag, digest
+}
+
+// splitDockerfileWords splits the arguments of an instruction into words
+// separated by whitespace, where quotes group words and are removed, and the
+// escape character escapes the next character.
+func splitDockerfileWords(s string, escape byte) []string {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == escape && i+1 < len(s) && quote != '\'':
+			i++
+			word.WriteByte(s[i])
+			inWord = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				word.WriteByte(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+			inWord = true
+		case c == ' ' || c == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
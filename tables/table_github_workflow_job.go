@@ -0,0 +1,290 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	"gopkg.in/yaml.v3"
+)
+
+func tableGitHubWorkflowJob(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "github_workflow_job",
+		Description: "List the jobs of GitHub Actions workflow files.",
+		List: &plugin.ListConfig{
+			Hydrate: listGitHubWorkflowJobs,
+			KeyColumns: plugin.KeyColumnSlice{
+				{
+					Name:    "path",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{Name: "path", Type: proto.ColumnType_STRING, Description: "Specifies the path of the workflow file."},
+			{Name: "workflow_name", Type: proto.ColumnType_STRING, Description: "The name of the workflow."},
+			{Name: "triggers", Type: proto.ColumnType_JSON, Description: "The events which trigger the workflow, mapped to their configuration."},
+			{Name: "workflow_permissions", Type: proto.ColumnType_JSON, Description: "The default permissions of the GITHUB_TOKEN for all jobs of the workflow."},
+			{Name: "workflow_env", Type: proto.ColumnType_JSON, Description: "The environment variables available to all jobs of the workflow."},
+			{Name: "job_id", Type: proto.ColumnType_STRING, Description: "The identifier of the job."},
+			{Name: "name", Type: proto.ColumnType_STRING, Description: "The name of the job."},
+			{Name: "runs_on", Type: proto.ColumnType_JSON, Description: "The type of machine the job runs on."},
+			{Name: "needs", Type: proto.ColumnType_JSON, Description: "The identifiers of the jobs which must complete before this job runs."},
+			{Name: "condition", Type: proto.ColumnType_STRING, Transform: transform.FromField("If"), Description: "The if condition which must be met for the job to run."},
+			{Name: "permissions", Type: proto.ColumnType_JSON, Description: "The permissions of the GITHUB_TOKEN for the job, overriding workflow_permissions."},
+			{Name: "environment", Type: proto.ColumnType_JSON, Description: "The deployment environment of the job."},
+			{Name: "env", Type: proto.ColumnType_JSON, Description: "The environment variables available to all steps of the job."},
+			{Name: "uses", Type: proto.ColumnType_STRING, Description: "The reusable workflow called by the job."},
+			{Name: "uses_type", Type: proto.ColumnType_STRING, Description: "The type of the reusable workflow, i.e. workflow for a workflow in another repository or local for a workflow in the same repository."},
+			{Name: "uses_owner", Type: proto.ColumnType_STRING, Description: "The owner of the repository of the reusable workflow."},
+			{Name: "uses_repo", Type: proto.ColumnType_STRING, Description: "The name of the repository of the reusable workflow."},
+			{Name: "uses_path", Type: proto.ColumnType_STRING, Description: "The path of the reusable workflow in its repository."},
+			{Name: "uses_ref", Type: proto.ColumnType_STRING, Description: "The Git ref of the reusable workflow, i.e. a branch, tag or commit SHA."},
+			{Name: "pinned_to_sha", Type: proto.ColumnType_BOOL, Description: "True if the reusable workflow is pinned to a full length commit SHA."},
+			{Name: "with", Type: proto.ColumnType_JSON, Description: "The inputs passed to the reusable workflow."},
+			{Name: "secrets", Type: proto.ColumnType_JSON, Description: "The secrets passed to the reusable workflow, or inherit."},
+			{Name: "strategy", Type: proto.ColumnType_JSON, Description: "The matrix strategy of the job."},
+			{Name: "container", Type: proto.ColumnType_JSON, Description: "The container the steps of the job run in."},
+			{Name: "services", Type: proto.ColumnType_JSON, Description: "The service containers of the job."},
+			{Name: "timeout_minutes", Type: proto.ColumnType_JSON, Description: "The maximum number of minutes the job can run for."},
+			{Name: "step_count", Type: proto.ColumnType_INT, Transform: transform.FromField("StepCount"), Description: "The number of steps of the job, or null if steps is not a list."},
+			{Name: "start_line", Type: proto.ColumnType_INT, Description: "The line number where the job starts."},
+			{Name: "end_line", Type: proto.ColumnType_INT, Description: "The line number where the job ends."},
+		},
+	}
+}
+
+type gitHubWorkflowJob struct {
+	gitHubWorkflowUses
+	Path                string
+	WorkflowName        interface{}
+	Triggers            interface{}
+	WorkflowPermissions interface{}
+	WorkflowEnv         interface{}
+	JobID               string
+	Name                interface{}
+	RunsOn              interface{}
+	Needs               interface{}
+	If                  interface{}
+	Permissions         interface{}
+	Environment         interface{}
+	Env                 interface{}
+	With                interface{}
+	Secrets             interface{}
+	Strategy            interface{}
+	Container           interface{}
+	Services            interface{}
+	TimeoutMinutes      interface{}
+	StepCount           *int
+	StartLine           int
+	EndLine             int
+}
+
+// gitHubWorkflowUses is the reference to an action or reusable workflow in a
+// uses attribute, split into its parts.
+type gitHubWorkflowUses struct {
+	Uses        string
+	UsesType    string
+	UsesOwner   string
+	UsesRepo    string
+	UsesPath    string
+	UsesRef     string
+	PinnedToSha *bool
+}
+
+func listGitHubWorkflowJobs(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	paths, err := gitHubWorkflowPaths(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		workflow, err := loadGitHubWorkflow(path)
+		if err != nil {
+			plugin.Logger(ctx).Error("github_workflow_job.listGitHubWorkflowJobs", "parse_error", err, "path", path)
+			return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
+		}
+
+		for _, job := range workflow.jobs {
+			row := gitHubWorkflowJob{
+				Path:                path,
+				WorkflowName:        workflow.name,
+				Triggers:            workflow.triggers,
+				WorkflowPermissions: workflow.permissions,
+				WorkflowEnv:         workflow.env,
+				JobID:               job.id,
+				StartLine:           job.line,
+				EndLine:             nodeEndLine(job.node),
+			}
+			content, _ := nodeToJSON(job.node).(map[string]interface{})
+			row.Name = content["name"]
+			row.RunsOn = content["runs-on"]
+			row.If = content["if"]
+			row.Permissions = content["permissions"]
+			row.Environment = content["environment"]
+			row.Env = content["env"]
+			row.With = content["with"]
+			row.Secrets = content["secrets"]
+			row.Strategy = content["strategy"]
+			row.Container = content["container"]
+			row.Services = content["services"]
+			row.TimeoutMinutes = content["timeout-minutes"]
+
+			// needs is either a single job or a list of jobs
+			switch needs := content["needs"].(type) {
+			case string:
+				row.Needs = []string{needs}
+			default:
+				row.Needs = needs
+			}
+			if uses, ok := content["uses"].(string); ok {
+				row.gitHubWorkflowUses = parseGitHubWorkflowUses(uses)
+			}
+			// Aliases are already resolved, so steps is only counted if it is a list
+			if steps := mappingValue(job.node, "steps"); steps != nil && steps.Kind == yaml.SequenceNode {
+				count := len(steps.Content)
+				row.StepCount = &count
+			}
+			d.StreamListItem(ctx, row)
+		}
+	}
+	return nil, nil
+}
+
+// gitHubWorkflowPaths returns the workflow files to query, either from the
+// path qual or from the github_workflow_paths config argument.
+func gitHubWorkflowPaths(ctx context.Context, d *plugin.QueryData) ([]string, error) {
+	// #1 - Path via qual
+	// If the path was requested through qualifier then match it exactly. Globs
+	// are not supported in this context since the output value for the column
+	// will never match the requested value.
+	if d.EqualsQuals["path"] != nil {
+		return []string{d.EqualsQuals["path"].GetStringValue()}, nil
+	}
+
+	// #2 - Path via glob paths in config
+	return listGitHubWorkflowFiles(ctx, d)
+}
+
+type gitHubWorkflow struct {
+	name        interface{}
+	triggers    interface{}
+	permissions interface{}
+	env         interface{}
+	jobs        []gitHubWorkflowJobNode
+}
+
+type gitHubWorkflowJobNode struct {
+	id   string
+	line int
+	node *yaml.Node
+}
+
+// loadGitHubWorkflow parses a workflow file, with YAML anchors and aliases
+// resolved. Workflows expanding to too many nodes through aliases are
+// rejected, as by resolveYAMLAliases.
+func loadGitHubWorkflow(path string) (*gitHubWorkflow, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	workflow := &gitHubWorkflow{}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return workflow, nil
+	}
+	root, err := resolveYAMLAliases(doc.Content[0])
+	if err != nil {
+		return nil, err
+	}
+
+	values, _ := nodeToJSON(root).(map[string]interface{})
+	workflow.name = values["name"]
+	workflow.permissions = values["permissions"]
+	workflow.env = values["env"]
+
+	// on is either a single event, a list of events or a mapping of events to
+	// their configuration. All forms are returned as a mapping.
+	switch on := values["on"].(type) {
+	case string:
+		workflow.triggers = map[string]interface{}{on: nil}
+	case []interface{}:
+		triggers := map[string]interface{}{}
+		for _, i := range on {
+			triggers[fmt.Sprint(i)] = nil
+		}
+		workflow.triggers = triggers
+	default:
+		workflow.triggers = on
+	}
+
+	if jobs := mappingValue(root, "jobs"); jobs != nil && jobs.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(jobs.Content); i += 2 {
+			if jobs.Content[i+1].Kind != yaml.MappingNode {
+				continue
+			}
+			workflow.jobs = append(workflow.jobs, gitHubWorkflowJobNode{
+				id:   jobs.Content[i].Value,
+				line: jobs.Content[i].Line,
+				node: jobs.Content[i+1],
+			})
+		}
+	}
+	return workflow, nil
+}
+
+var gitHubCommitSHARegex = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// parseGitHubWorkflowUses splits the uses attribute of a job or step. The
+// forms are owner/repo[/path]@ref for actions and reusable workflows in other
+// repositories, ./path for local actions and workflows, and docker://image
+// for Docker Hub images.
+func parseGitHubWorkflowUses(uses string) gitHubWorkflowUses {
+	result := gitHubWorkflowUses{Uses: uses}
+	switch {
+	case strings.HasPrefix(uses, "./"):
+		result.UsesType = "local"
+		result.UsesPath = strings.TrimPrefix(uses, "./")
+
+	case strings.HasPrefix(uses, "docker://"):
+		result.UsesType = "docker"
+		image := strings.TrimPrefix(uses, "docker://")
+		pinned := false
+		if name, digest, ok := strings.Cut(image, "@"); ok {
+			image, result.UsesRef = name, digest
+			pinned = strings.HasPrefix(digest, "sha256:")
+		} else if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+			image, result.UsesRef = image[:i], image[i+1:]
+		}
+		result.UsesPath = image
+		result.PinnedToSha = &pinned
+
+	default:
+		result.UsesType = "action"
+		name, ref, _ := strings.Cut(uses, "@")
+		parts := strings.SplitN(name, "/", 3)
+		result.UsesOwner = parts[0]
+		if len(parts) > 1 {
+			result.UsesRepo = parts[1]
+		}
+		if len(parts) > 2 {
+			result.UsesPath = parts[2]
+			if strings.HasPrefix(parts[2], ".github/workflows/") {
+				result.UsesType = "workflow"
+			}
+		}
+		result.UsesRef = ref
+		pinned := gitHubCommitSHARegex.MatchString(ref)
+		result.PinnedToSha = &pinned
+	}
+	return result
+}
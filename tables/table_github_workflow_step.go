@@ -0,0 +1,123 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	"gopkg.in/yaml.v3"
+)
+
+func tableGitHubWorkflowStep(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "github_workflow_step",
+		Description: "List the steps of the jobs of GitHub Actions workflow files.",
+		List: &plugin.ListConfig{
+			Hydrate: listGitHubWorkflowSteps,
+			KeyColumns: plugin.KeyColumnSlice{
+				{
+					Name:    "path",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{Name: "path", Type: proto.ColumnType_STRING, Description: "Specifies the path of the workflow file."},
+			{Name: "workflow_name", Type: proto.ColumnType_STRING, Description: "The name of the workflow."},
+			{Name: "job_id", Type: proto.ColumnType_STRING, Description: "The identifier of the job of the step."},
+			{Name: "step_index", Type: proto.ColumnType_INT, Transform: transform.FromField("StepIndex"), Description: "The zero-based position of the step in the job."},
+			{Name: "step_id", Type: proto.ColumnType_STRING, Transform: transform.FromField("StepID"), Description: "The identifier of the step."},
+			{Name: "name", Type: proto.ColumnType_STRING, Description: "The name of the step."},
+			{Name: "condition", Type: proto.ColumnType_STRING, Transform: transform.FromField("If"), Description: "The if condition which must be met for the step to run."},
+			{Name: "uses", Type: proto.ColumnType_STRING, Description: "The action run by the step."},
+			{Name: "uses_type", Type: proto.ColumnType_STRING, Description: "The type of the action, i.e. action for an action in a repository, local for an action in the same repository or docker for a Docker Hub image."},
+			{Name: "uses_owner", Type: proto.ColumnType_STRING, Description: "The owner of the repository of the action."},
+			{Name: "uses_repo", Type: proto.ColumnType_STRING, Description: "The name of the repository of the action."},
+			{Name: "uses_path", Type: proto.ColumnType_STRING, Description: "The path of the action in its repository, or the name of the Docker image."},
+			{Name: "uses_ref", Type: proto.ColumnType_STRING, Description: "The Git ref of the action, i.e. a branch, tag or commit SHA, or the tag or digest of the Docker image."},
+			{Name: "pinned_to_sha", Type: proto.ColumnType_BOOL, Description: "True if the action is pinned to a full length commit SHA, or the Docker image to a digest."},
+			{Name: "run", Type: proto.ColumnType_STRING, Description: "The script run by the step."},
+			{Name: "shell", Type: proto.ColumnType_STRING, Description: "The shell the script is run with."},
+			{Name: "working_directory", Type: proto.ColumnType_STRING, Description: "The working directory the script is run in."},
+			{Name: "with", Type: proto.ColumnType_JSON, Description: "The inputs passed to the action."},
+			{Name: "env", Type: proto.ColumnType_JSON, Description: "The environment variables of the step."},
+			{Name: "continue_on_error", Type: proto.ColumnType_JSON, Description: "Whether the job continues when the step fails."},
+			{Name: "timeout_minutes", Type: proto.ColumnType_JSON, Description: "The maximum number of minutes the step can run for."},
+			{Name: "start_line", Type: proto.ColumnType_INT, Description: "The line number where the step starts."},
+			{Name: "end_line", Type: proto.ColumnType_INT, Description: "The line number where the step ends."},
+		},
+	}
+}
+
+type gitHubWorkflowStep struct {
+	gitHubWorkflowUses
+	Path             string
+	WorkflowName     interface{}
+	JobID            string
+	StepIndex        int
+	StepID           interface{}
+	Name             interface{}
+	If               interface{}
+	Run              interface{}
+	Shell            interface{}
+	WorkingDirectory interface{}
+	With             interface{}
+	Env              interface{}
+	ContinueOnError  interface{}
+	TimeoutMinutes   interface{}
+	StartLine        int
+	EndLine          int
+}
+
+func listGitHubWorkflowSteps(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	paths, err := gitHubWorkflowPaths(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		workflow, err := loadGitHubWorkflow(path)
+		if err != nil {
+			plugin.Logger(ctx).Error("github_workflow_step.listGitHubWorkflowSteps", "parse_error", err, "path", path)
+			return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
+		}
+
+		for _, job := range workflow.jobs {
+			steps := mappingValue(job.node, "steps")
+			if steps == nil || steps.Kind != yaml.SequenceNode {
+				continue
+			}
+			for i, step := range steps.Content {
+				if step.Kind != yaml.MappingNode {
+					continue
+				}
+				row := gitHubWorkflowStep{
+					Path:         path,
+					WorkflowName: workflow.name,
+					JobID:        job.id,
+					StepIndex:    i,
+					StartLine:    step.Line,
+					EndLine:      nodeEndLine(step),
+				}
+				content, _ := nodeToJSON(step).(map[string]interface{})
+				row.StepID = content["id"]
+				row.Name = content["name"]
+				row.If = content["if"]
+				row.Run = content["run"]
+				row.Shell = content["shell"]
+				row.WorkingDirectory = content["working-directory"]
+				row.With = content["with"]
+				row.Env = content["env"]
+				row.ContinueOnError = content["continue-on-error"]
+				row.TimeoutMinutes = content["timeout-minutes"]
+				if uses, ok := content["uses"].(string); ok {
+					row.gitHubWorkflowUses = parseGitHubWorkflowUses(uses)
+				}
+				d.StreamListItem(ctx, row)
+			}
+		}
+	}
+	return nil, nil
+}
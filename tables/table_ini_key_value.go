@@ -4,13 +4,14 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"regexp"
 	"strings"
+	"time"
 
 	"gopkg.in/ini.v1"
 
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 )
 
 func tableINIKeyValue(ctx context.Context) *plugin.Table {
@@ -30,18 +31,36 @@ func tableINIKeyValue(ctx context.Context) *plugin.Table {
 			{Name: "path", Type: proto.ColumnType_STRING, Description: "Specifies the path of the INI file."},
 			{Name: "section", Type: proto.ColumnType_STRING, Description: "Specifies the name of the section."},
 			{Name: "key", Type: proto.ColumnType_STRING, Description: "The name of the key."},
-			{Name: "value", Type: proto.ColumnType_STRING, Description: "The value of corresponding key."},
+			{Name: "value", Type: proto.ColumnType_STRING, Description: "The value of corresponding key, with all variable references resolved."},
+			{Name: "value_number", Type: proto.ColumnType_DOUBLE, Transform: transform.FromField("ValueNumber"), Description: "The value of the corresponding key, if it is a number."},
+			{Name: "value_bool", Type: proto.ColumnType_BOOL, Transform: transform.FromField("ValueBool"), Description: "The value of the corresponding key, if it is a boolean, e.g. true, yes, on or 1."},
+			{Name: "value_timestamp", Type: proto.ColumnType_TIMESTAMP, Transform: transform.FromField("ValueTimestamp"), Description: "The value of the corresponding key, if it is a timestamp or date."},
+			{Name: "value_json", Type: proto.ColumnType_JSON, Transform: transform.FromField("ValueJSON"), Description: "The value of the corresponding key as a typed JSON value, i.e. a number, boolean or string."},
+			{Name: "raw_value", Type: proto.ColumnType_STRING, Description: "The value of corresponding key as written in the file, without resolving variable references."},
+			{Name: "unresolved_references", Type: proto.ColumnType_JSON, Description: "A list of variable references in the value that could not be resolved."},
 			{Name: "comment", Type: proto.ColumnType_STRING, Description: "The short notes used to describe the key."},
+			{Name: "occurrence", Type: proto.ColumnType_INT, Transform: transform.FromField("Occurrence"), Description: "The zero-based index of the key among repeated keys with the same name in the section. Each occurrence of a repeated key is returned as a separate row, whether or not allow_shadows is enabled in ini_options."},
+			{Name: "line", Type: proto.ColumnType_INT, Description: "The line number where the key is located."},
+			{Name: "section_line", Type: proto.ColumnType_INT, Description: "The line number of the section header. Not set for keys in the default section."},
 		},
 	}
 }
 
 type parseFormat struct {
-	Path    string
-	Section string
-	Key     string
-	Value   string
-	Comment string
+	Path                 string
+	Section              string
+	Key                  string
+	Value                string
+	ValueNumber          *float64
+	ValueBool            *bool
+	ValueTimestamp       *time.Time
+	ValueJSON            interface{}
+	RawValue             string
+	Comment              string
+	UnresolvedReferences []string
+	Occurrence           int
+	Line                 int
+	SectionLine          int
 }
 
 func listINIWithPath(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
@@ -64,54 +83,228 @@ func listINIWithPath(ctx context.Context, d *plugin.QueryData, h *plugin.Hydrate
 
 	for _, path := range paths {
 		// Load file
-		var opts ini.LoadOptions
-		opts.AllowPythonMultilineValues = true
-		cfg, err := ini.LoadSources(opts, path)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			plugin.Logger(ctx).Error("ini_key_value.listINIWithPath", "file_error", err, "path", path)
+			return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
+		}
+		cfg, lines, err := loadINIContent(d, content)
 		if err != nil {
 			plugin.Logger(ctx).Error("ini_key_value.listINIWithPath", "file_error", err, "path", path)
 			return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
 		}
 
+		shadows, err := loadINIShadows(d, content, cfg)
+		if err != nil {
+			plugin.Logger(ctx).Error("ini_key_value.listINIWithPath", "file_error", err, "path", path)
+			return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
+		}
+
+		// Sections with the same name are only kept apart if allow_non_unique_sections is enabled
+		occurrences := map[string]int{}
 		for _, i := range cfg.Sections() {
+			sectionLines := lines.section(i.Name(), occurrences[i.Name()])
+			shadowSection := iniShadowSection(shadows, i, occurrences[i.Name()])
+			occurrences[i.Name()]++
+
 			// Extract keys of a section
-			for _, key := range cfg.Section(i.Name()).Keys() {
-				d.StreamListItem(ctx, formatResult(cfg, path, i.Name(), key.Name(), key.String(), key.Comment))
+			for _, key := range i.Keys() {
+				values := iniKeyValues(shadowSection, key)
+				for occurrence, value := range values {
+					row := formatResult(cfg, path, i.Name(), key.Name(), value, key.Comment)
+					row.Occurrence = occurrence
+					row.Line = sectionLines.keyLine(key.Name(), occurrence, len(values))
+					row.SectionLine = sectionLines.Line
+					d.StreamListItem(ctx, row)
+				}
 			}
 		}
 	}
 	return nil, nil
 }
 
-func formatResult(cfg *ini.File, filePath string, secton string, key string, val string, comment string) parseFormat {
+func formatResult(cfg *ini.File, filePath string, section string, key string, val string, comment string) parseFormat {
+	value, unresolved := parseValue(cfg, section, key, val)
 	return parseFormat{
-		Path:    filePath,
-		Section: secton,
-		Key:     key,
-		Value:   parseValue(cfg, val),
-		Comment: comment,
+		Path:                 filePath,
+		Section:              section,
+		Key:                  key,
+		Value:                value,
+		ValueNumber:          parseNumber(value),
+		ValueBool:            parseBool(value),
+		ValueTimestamp:       parseTimestamp(value),
+		ValueJSON:            iniJSONValue(value),
+		RawValue:             val,
+		Comment:              comment,
+		UnresolvedReferences: unresolved,
+	}
+}
+
+// iniJSONValue coerces an INI value to a number or boolean where possible,
+// otherwise it is returned as a string. Unlike value_bool, 1 and 0 are
+// treated as numbers.
+func iniJSONValue(value string) interface{} {
+	if n := parseNumber(value); n != nil {
+		return *n
+	}
+	if b := parseBool(value); b != nil {
+		return *b
+	}
+	return value
+}
+
+// parseValue will parse env variable and other variable references with its actual value.
+// It returns the resolved value along with the list of references that could not be resolved.
+func parseValue(cfg *ini.File, section string, key string, str string) (string, []string) {
+	r := iniResolver{cfg: cfg, visiting: map[string]bool{section + "\x00" + key: true}, resolved: map[string]string{}}
+	value := r.resolve(section, str)
+	return value, r.unresolved
+}
+
+// iniMaxValueLength bounds the length of resolved values, since keys referring
+// several times to other keys grow exponentially.
+const iniMaxValueLength = 64 << 10
+
+type iniResolver struct {
+	cfg        *ini.File
+	visiting   map[string]bool
+	resolved   map[string]string
+	unresolved []string
+}
+
+// resolve replaces every ${name}, ${name:-default} and %(name)s reference in
+// str, recursively resolving references found in the substituted values.
+// References which cannot be resolved, which would lead to a cycle, or which
+// would make the value longer than iniMaxValueLength are left as-is in the
+// returned value.
+func (r *iniResolver) resolve(section string, str string) string {
+	var sb strings.Builder
+	for {
+		start, end := nextINIReference(str)
+		if start < 0 {
+			sb.WriteString(str)
+			return sb.String()
+		}
+		sb.WriteString(str[:start])
+		ref := str[start:end]
+		str = str[end:]
+
+		// Python ConfigParser style, i.e. path = %(home_dir)s/bin
+		if strings.HasPrefix(ref, "%(") {
+			if value, ok := r.lookupKey(section, ref[2:len(ref)-2]); ok && r.fits(&sb, value, str) {
+				sb.WriteString(value)
+				continue
+			}
+			r.markUnresolved(ref)
+			sb.WriteString(ref)
+			continue
+		}
+
+		name, defaultValue, hasDefault := strings.Cut(ref[2:len(ref)-1], ":-")
+		value, ok := r.lookup(section, name)
+		if !ok && hasDefault {
+			value, ok = r.resolve(section, defaultValue), true
+		}
+		if ok && r.fits(&sb, value, str) {
+			sb.WriteString(value)
+		} else {
+			r.markUnresolved(ref)
+			sb.WriteString(ref)
+		}
 	}
 }
 
-// parseValue will parse env variable and other variable references with its actual value
-func parseValue(cfg *ini.File, str string) string {
-	// Check for value of the environment variable references
-	isEnvVar, _ := regexp.MatchString(".*\\${.*}.*", str)
-	if isEnvVar {
-		regexExp := regexp.MustCompile(`\$\{(.*?)\}`)
-		matchedStr := regexExp.FindStringSubmatch(str)
-		if len(matchedStr) > 1 {
-			// Check for reference from other section, i.e. path = ${Common.system_dir}/Library/Frameworks/
-			if strings.Contains(matchedStr[1], ".") {
-				splitStr := strings.Split(matchedStr[1], ".")
-				sec := strings.Join(splitStr[:len(splitStr)-1], ".")
-				key := splitStr[len(splitStr)-1]
-				value := cfg.Section(sec).Key(key).String()
-				str = strings.Replace(str, matchedStr[0], value, -1)
-			} else {
-				// Replace the matched string with env variable value
-				str = strings.Replace(str, matchedStr[0], os.Getenv(matchedStr[1]), -1)
+// fits returns true if a value can be substituted in the value being built
+// without exceeding iniMaxValueLength, given the rest of the value.
+func (r *iniResolver) fits(sb *strings.Builder, value string, rest string) bool {
+	return sb.Len()+len(value)+len(rest) <= iniMaxValueLength
+}
+
+// nextINIReference returns the bounds of the first reference in str, or -1 if
+// there is none. Braces are balanced so that default values may contain
+// references themselves, i.e. ${DATA_DIR:-${HOME}/data}.
+func nextINIReference(str string) (int, int) {
+	for i := 0; i < len(str)-1; i++ {
+		switch str[i : i+2] {
+		case "${":
+			depth := 0
+			for j := i + 1; j < len(str); j++ {
+				switch str[j] {
+				case '{':
+					depth++
+				case '}':
+					depth--
+					if depth == 0 {
+						return i, j + 1
+					}
+				}
+			}
+		case "%(":
+			if j := strings.Index(str[i:], ")s"); j > 2 {
+				return i, i + j + 2
 			}
 		}
 	}
-	return str
+	return -1, -1
+}
+
+// lookup resolves a ${name} reference. A dotted name refers to a key in
+// another section, i.e. path = ${Common.system_dir}/Library/Frameworks/, if
+// that section and key exist; otherwise the name is treated as an environment
+// variable. Undotted names are looked up in the environment first, then in
+// the current and default sections.
+func (r *iniResolver) lookup(section string, name string) (string, bool) {
+	if i := strings.LastIndex(name, "."); i > 0 {
+		if value, ok := r.lookupSectionKey(name[:i], name[i+1:]); ok {
+			return value, true
+		}
+	}
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true
+	}
+	return r.lookupKey(section, name)
+}
+
+// lookupKey resolves a key in the given section, falling back to the default section.
+func (r *iniResolver) lookupKey(section string, key string) (string, bool) {
+	if value, ok := r.lookupSectionKey(section, key); ok {
+		return value, true
+	}
+	return r.lookupSectionKey(ini.DefaultSection, key)
+}
+
+func (r *iniResolver) lookupSectionKey(section string, key string) (string, bool) {
+	sec, err := r.cfg.GetSection(section)
+	if err != nil {
+		return "", false
+	}
+	k, err := sec.GetKey(key)
+	if err != nil {
+		return "", false
+	}
+
+	// Stop if the key is already being resolved, i.e. a = ${b}, b = ${a}, and
+	// resolve each key once, as keys may be referred to many times
+	id := sec.Name() + "\x00" + k.Name()
+	if value, ok := r.resolved[id]; ok {
+		return value, true
+	}
+	if r.visiting[id] {
+		return "", false
+	}
+	r.visiting[id] = true
+	defer delete(r.visiting, id)
+
+	value := r.resolve(sec.Name(), k.Value())
+	r.resolved[id] = value
+	return value, true
+}
+
+func (r *iniResolver) markUnresolved(ref string) {
+	for _, i := range r.unresolved {
+		if i == ref {
+			return
+		}
+	}
+	r.unresolved = append(r.unresolved, ref)
 }
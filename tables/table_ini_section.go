@@ -3,11 +3,15 @@ package config
 import (
 	"context"
 	"fmt"
+	"os"
+	"regexp"
+	"strings"
 
 	"gopkg.in/ini.v1"
 
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 )
 
 func tableINISection(ctx context.Context) *plugin.Table {
@@ -27,16 +31,31 @@ func tableINISection(ctx context.Context) *plugin.Table {
 			{Name: "path", Type: proto.ColumnType_STRING, Description: "Specifies the path of the INI file."},
 			{Name: "section", Type: proto.ColumnType_STRING, Description: "Specifies the name of the section."},
 			{Name: "comment", Type: proto.ColumnType_STRING, Description: "The short notes used to describe the key."},
+			{Name: "parent_section", Type: proto.ColumnType_STRING, Description: "The name of the parent section, e.g. remote for [remote \"origin\"] or auth for [auth.google]."},
+			{Name: "subsection_name", Type: proto.ColumnType_STRING, Description: "The name of the subsection, e.g. origin for [remote \"origin\"] or google for [auth.google]."},
+			{Name: "key_count", Type: proto.ColumnType_INT, Transform: transform.FromField("KeyCount"), Description: "The number of keys defined in the section, counting every occurrence of a repeated key."},
+			{Name: "start_line", Type: proto.ColumnType_INT, Description: "The line number where the section starts."},
+			{Name: "end_line", Type: proto.ColumnType_INT, Description: "The line number where the section ends."},
+			{Name: "keys", Type: proto.ColumnType_JSON, Description: "A map of the keys in the section to their values. Repeated keys map to an array of values."},
 		},
 	}
 }
 
 type parseSectionFormat struct {
-	Path    string
-	Section string
-	Comment string
+	Path           string
+	Section        string
+	Comment        string
+	ParentSection  string
+	SubsectionName string
+	KeyCount       int
+	StartLine      int
+	EndLine        int
+	Keys           map[string]interface{}
 }
 
+// gitSubsectionRegex matches git config style subsections, i.e. [remote "origin"]
+var gitSubsectionRegex = regexp.MustCompile(`^(\S+)\s+"(.*)"$`)
+
 func listINISections(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
 	// #1 - Path via qual
 	// If the path was requested through qualifier then match it exactly. Globs
@@ -57,16 +76,73 @@ func listINISections(ctx context.Context, d *plugin.QueryData, h *plugin.Hydrate
 
 	for _, path := range paths {
 		// Load file
-		var opts ini.LoadOptions
-		cfg, err := ini.LoadSources(opts, path)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			plugin.Logger(ctx).Error("ini_section.listINISections", "file_error", err, "path", path)
+			return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
+		}
+		cfg, lines, err := loadINIContent(d, content)
+		if err != nil {
+			plugin.Logger(ctx).Error("ini_section.listINISections", "file_error", err, "path", path)
+			return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
+		}
+		shadows, err := loadINIShadows(d, content, cfg)
 		if err != nil {
 			plugin.Logger(ctx).Error("ini_section.listINISections", "file_error", err, "path", path)
 			return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
 		}
 
+		occurrences := map[string]int{}
 		for _, i := range cfg.Sections() {
-			d.StreamListItem(ctx, parseSectionFormat{path, i.Name(), i.Comment})
+			sectionLines := lines.section(i.Name(), occurrences[i.Name()])
+			shadowSection := iniShadowSection(shadows, i, occurrences[i.Name()])
+			occurrences[i.Name()]++
+
+			row := parseSectionFormat{
+				Path:      path,
+				Section:   i.Name(),
+				Comment:   i.Comment,
+				StartLine: sectionLines.StartLine,
+				EndLine:   sectionLines.EndLine,
+			}
+			row.Keys, row.KeyCount = sectionKeys(cfg, i, shadowSection)
+			row.ParentSection, row.SubsectionName = splitSectionName(i.Name())
+			d.StreamListItem(ctx, row)
 		}
 	}
 	return nil, nil
 }
+
+// splitSectionName returns the parent section and subsection names of git
+// config style subsections, i.e. [remote "origin"], and of dotted child
+// sections, i.e. [auth.google].
+func splitSectionName(name string) (string, string) {
+	if match := gitSubsectionRegex.FindStringSubmatch(name); match != nil {
+		return match[1], match[2]
+	}
+	if i := strings.LastIndex(name, "."); i > 0 {
+		return name[:i], name[i+1:]
+	}
+	return "", ""
+}
+
+// sectionKeys returns the resolved values of the keys in a section, along
+// with the number of keys counting every occurrence of a repeated key.
+func sectionKeys(cfg *ini.File, section *ini.Section, shadowSection *ini.Section) (map[string]interface{}, int) {
+	keys := map[string]interface{}{}
+	count := 0
+	for _, key := range section.Keys() {
+		values := iniKeyValues(shadowSection, key)
+		count += len(values)
+		if len(values) == 1 {
+			keys[key.Name()], _ = parseValue(cfg, section.Name(), key.Name(), values[0])
+			continue
+		}
+		resolved := make([]string, len(values))
+		for i, v := range values {
+			resolved[i], _ = parseValue(cfg, section.Name(), key.Name(), v)
+		}
+		keys[key.Name()] = resolved
+	}
+	return keys, count
+}
@@ -2,7 +2,6 @@ package config
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -70,13 +69,18 @@ func listJSONFileWithPath(ctx context.Context, d *plugin.QueryData, h *plugin.Hy
 			return nil, fmt.Errorf("failed to read file content %s: %v", path, err)
 		}
 
-		// Load either JSON objects or JSON arrays
-		var result interface{}
-		err = json.Unmarshal([]byte(byteValue), &result)
+		// Load either JSON objects or JSON arrays, in the dialect configured for
+		// the file
+		root, err := loadJSONNode(d, path, byteValue)
 		if err != nil {
 			plugin.Logger(ctx).Error("json_file.listJSONFileWithPath", "parse_error", err, "path", path)
 			return nil, fmt.Errorf("failed to unmarshal file content %s: %v", path, err)
 		}
+		var result interface{}
+		if err := root.Decode(&result); err != nil {
+			plugin.Logger(ctx).Error("json_file.listJSONFileWithPath", "parse_error", err, "path", path)
+			return nil, fmt.Errorf("failed to unmarshal file content %s: %v", path, err)
+		}
 		d.StreamListItem(ctx, parseJSONContent{path, result})
 	}
 	return nil, nil
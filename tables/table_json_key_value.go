@@ -2,13 +2,14 @@ package config
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
-	"gopkg.in/yaml.v3"
 )
 
 func tableJSONKeyValue(ctx context.Context) *plugin.Table {
@@ -22,15 +23,31 @@ func tableJSONKeyValue(ctx context.Context) *plugin.Table {
 					Name:    "path",
 					Require: plugin.Optional,
 				},
+				{
+					Name:    "include_containers",
+					Require: plugin.Optional,
+				},
 			},
 		},
 		Columns: []*plugin.Column{
 			{Name: "path", Type: proto.ColumnType_STRING, Description: "Specifies the path of the JSON file."},
 			{Name: "key_path", Type: proto.ColumnType_LTREE, Transform: transform.FromField("Key").Transform(keysToSnakeCase), Description: "Specifies full path of a key in JSON file."},
 			{Name: "value", Type: proto.ColumnType_STRING, Description: "Specifies the value of the corresponding key."},
+			{Name: "value_number", Type: proto.ColumnType_DOUBLE, Transform: transform.FromField("ValueNumber"), Description: "The value of the corresponding key, if it is a number."},
+			{Name: "value_bool", Type: proto.ColumnType_BOOL, Transform: transform.FromField("ValueBool"), Description: "The value of the corresponding key, if it is a boolean."},
+			{Name: "value_timestamp", Type: proto.ColumnType_TIMESTAMP, Transform: transform.FromField("ValueTimestamp"), Description: "The value of the corresponding key, if it is a string holding a timestamp or date."},
+			{Name: "value_json", Type: proto.ColumnType_JSON, Transform: transform.FromField("ValueJSON"), Description: "The value of the corresponding key as a typed JSON value."},
 			{Name: "keys", Type: proto.ColumnType_JSON, Transform: transform.FromField("Key"), Description: "The array representation of path of a key."},
+			{Name: "node_kind", Type: proto.ColumnType_STRING, Description: "The kind of the node, i.e. scalar, mapping or sequence."},
+			{Name: "child_count", Type: proto.ColumnType_INT, Transform: transform.FromField("ChildCount"), Description: "The number of entries in a mapping or sequence."},
+			{Name: "include_containers", Type: proto.ColumnType_BOOL, Transform: transform.FromQual("include_containers"), Description: "If true, rows are also returned for non-empty objects and arrays, with the subtree in value_json. Defaults to false."},
+			{Name: "pre_comments", Type: proto.ColumnType_JSON, Description: "Specifies the comments added above a key, in JSONC and JSON5 files."},
+			{Name: "head_comment", Type: proto.ColumnType_STRING, Description: "Specifies the comment in the lines preceding the key."},
+			{Name: "line_comment", Type: proto.ColumnType_STRING, Description: "Specifies the comment at the end of the line of the value."},
+			{Name: "foot_comment", Type: proto.ColumnType_STRING, Description: "Specifies the comment following the last key of an object."},
 			{Name: "start_line", Type: proto.ColumnType_INT, Description: "Specifies the line number where the value is located."},
 			{Name: "start_column", Type: proto.ColumnType_INT, Description: "Specifies the starting column of the value."},
+			{Name: "end_line", Type: proto.ColumnType_INT, Description: "Specifies the last line of the value, including all descendants of an object or array."},
 		},
 	}
 }
@@ -42,36 +59,79 @@ func listJSONKeyValue(ctx context.Context, d *plugin.QueryData, h *plugin.Hydrat
 	// will never match the requested value.
 	//
 	// #2 - Path via glob paths in config
+	// Files matched by jsonl_paths are JSON Lines files, as are files with a
+	// JSON Lines extension requested through the qualifier.
 	var paths []string
+	jsonl := map[string]bool{}
 	if d.EqualsQuals["path"] != nil {
-		paths = []string{d.EqualsQuals["path"].GetStringValue()}
+		path := d.EqualsQuals["path"].GetStringValue()
+		paths = []string{path}
+		jsonl[path] = isJSONLPath(path)
 	} else {
-		var err error
-		paths, err = listJSONFiles(ctx, d)
-		if err != nil {
-			return nil, err
+		cfg := GetConfig(d.Connection)
+		if cfg.JSONPaths == nil && cfg.JSONLPaths == nil {
+			return nil, errors.New("json_paths or jsonl_paths must be configured to query JSON files")
+		}
+		if cfg.JSONPaths != nil {
+			jsonPaths, err := listJSONFiles(ctx, d)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, jsonPaths...)
+		}
+		if cfg.JSONLPaths != nil {
+			jsonlPaths, err := listJSONLFiles(ctx, d)
+			if err != nil {
+				return nil, err
+			}
+			for _, i := range jsonlPaths {
+				jsonl[i] = true
+			}
+			paths = append(paths, jsonlPaths...)
 		}
 	}
 
+	includeContainers := d.EqualsQuals["include_containers"] != nil && d.EqualsQuals["include_containers"].GetBoolValue()
 	for _, path := range paths {
 		// Read file content
-		reader, err := os.Open(path)
+		content, err := os.ReadFile(path)
 		if err != nil {
 			// Could not open the file, so log and ignore
 			plugin.Logger(ctx).Error("json_key_value.listJSONKeyValue", "file_error", err, "path", path)
 			return nil, nil
 		}
 
-		var root yaml.Node
-		decoder := yaml.NewDecoder(reader)
-		err = decoder.Decode(&root)
+		// The rows of each line of a JSON Lines file are keyed by the zero-based
+		// index of the line, i.e. its line number minus one, and lines which are
+		// not valid JSON are skipped
+		if jsonl[path] {
+			lines, err := parseJSONLines(d, path, content)
+			if err != nil {
+				return nil, err
+			}
+			for _, line := range lines {
+				if line.node == nil {
+					plugin.Logger(ctx).Warn("json_key_value.listJSONKeyValue", "parse_error", line.Error, "path", path, "line", line.LineNumber)
+					continue
+				}
+				var rows Rows
+				treeToList(line.node, []string{strconv.Itoa(line.LineNumber - 1)}, &rows, nil, nil, nil, includeContainers)
+				for _, r := range rows {
+					r.Path = path
+					d.StreamListItem(ctx, r)
+				}
+			}
+			continue
+		}
+
+		root, err := loadJSONNode(d, path, content)
 		if err != nil {
 			plugin.Logger(ctx).Error("json_key_value.listJSONKeyValue", "parse_error", err, "path", path)
 			return nil, fmt.Errorf("failed to parse file: %v", err)
 		}
 
 		var rows Rows
-		treeToList(&root, []string{}, &rows, nil, nil, nil)
+		treeToList(root, []string{}, &rows, nil, nil, nil, includeContainers)
 		for _, r := range rows {
 			r.Path = path
 			d.StreamListItem(ctx, r)
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	"gopkg.in/yaml.v3"
+)
+
+func tableJSONLFile(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "jsonl_file",
+		Description: "List the lines of JSON Lines (NDJSON) files, with one JSON value per line.",
+		List: &plugin.ListConfig{
+			Hydrate: listJSONLFileWithPath,
+			KeyColumns: plugin.KeyColumnSlice{
+				{
+					Name:    "path",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{Name: "path", Type: proto.ColumnType_STRING, Description: "Specifies the path of the JSON Lines file."},
+			{Name: "line_number", Type: proto.ColumnType_INT, Transform: transform.FromField("LineNumber"), Description: "The line number of the value in the file. Blank lines are skipped."},
+			{Name: "content", Type: proto.ColumnType_JSON, Description: "The JSON value of the line, or null if the line could not be parsed."},
+			{Name: "error", Type: proto.ColumnType_STRING, Description: "The error parsing the line, if it is not valid JSON."},
+		},
+	}
+}
+
+type jsonLine struct {
+	Path       string
+	LineNumber int
+	Content    interface{}
+	Error      string
+
+	// node is the parsed value, with line numbers relative to the file
+	node *yaml.Node
+}
+
+func listJSONLFileWithPath(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// #1 - Path via qual
+	// If the path was requested through qualifier then match it exactly. Globs
+	// are not supported in this context since the output value for the column
+	// will never match the requested value.
+	//
+	// #2 - Path via glob paths in config
+	var paths []string
+	if d.EqualsQuals["path"] != nil {
+		paths = []string{d.EqualsQuals["path"].GetStringValue()}
+	} else {
+		var err error
+		paths, err = listJSONLFiles(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			plugin.Logger(ctx).Error("jsonl_file.listJSONLFileWithPath", "file_error", err, "path", path)
+			return nil, fmt.Errorf("fail to read file %s: %v", path, err)
+		}
+		lines, err := parseJSONLines(d, path, content)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range lines {
+			d.StreamListItem(ctx, line)
+		}
+	}
+	return nil, nil
+}
+
+// isJSONLPath returns true if the path has the extension of a JSON Lines file.
+func isJSONLPath(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson", ".jsonlines":
+		return true
+	}
+	return false
+}
+
+// parseJSONLines parses each non-blank line of a JSON Lines file as a JSON
+// value in the dialect configured for the file. Lines which are not valid are
+// returned with their parse error rather than failing the whole file, since a
+// truncated last line is common in logs which are still being written.
+func parseJSONLines(d *plugin.QueryData, path string, content []byte) ([]jsonLine, error) {
+	dialect, err := jsonDialect(d, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []jsonLine
+	for n, text := range strings.Split(string(content), "\n") {
+		text = strings.TrimSuffix(text, "\r")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		line := jsonLine{Path: path, LineNumber: n + 1}
+		node, err := parseJSONNode([]byte(text), dialect)
+		if err == nil {
+			err = node.Decode(&line.Content)
+		}
+		if err != nil {
+			line.Error = err.Error()
+		} else {
+			shiftNodeLines(node, n)
+			line.node = node
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// shiftNodeLines adds an offset to the line numbers of a node tree.
+func shiftNodeLines(node *yaml.Node, offset int) {
+	node.Line += offset
+	for _, i := range node.Content {
+		shiftNodeLines(i, offset)
+	}
+}
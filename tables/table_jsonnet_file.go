@@ -0,0 +1,96 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/go-jsonnet"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+func tableJsonnetFile(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "jsonnet_file",
+		Description: "Represents the JSON document produced by evaluating Jsonnet files.",
+		List: &plugin.ListConfig{
+			Hydrate: listJsonnetFileWithPath,
+			KeyColumns: plugin.KeyColumnSlice{
+				{
+					Name:    "path",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{Name: "path", Type: proto.ColumnType_STRING, Description: "Specifies the path of the Jsonnet file."},
+			{Name: "content", Type: proto.ColumnType_JSON, Description: "The JSON document produced by evaluating the file."},
+		},
+	}
+}
+
+type parseJsonnetContent struct {
+	Path    string
+	Content interface{}
+}
+
+func listJsonnetFileWithPath(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// #1 - Path via qual
+	// If the path was requested through qualifier then match it exactly. Globs
+	// are not supported in this context since the output value for the column
+	// will never match the requested value.
+	//
+	// #2 - Path via glob paths in config
+	var paths []string
+	if d.EqualsQuals["path"] != nil {
+		paths = []string{d.EqualsQuals["path"].GetStringValue()}
+	} else {
+		var err error
+		paths, err = listJsonnetFiles(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range paths {
+		output, err := evaluateJsonnet(d, path)
+		if err != nil {
+			plugin.Logger(ctx).Error("jsonnet_file.listJsonnetFileWithPath", "evaluation_error", err, "path", path)
+			return nil, fmt.Errorf("failed to evaluate file %s: %v", path, err)
+		}
+		var result interface{}
+		if err := json.Unmarshal(output, &result); err != nil {
+			plugin.Logger(ctx).Error("jsonnet_file.listJsonnetFileWithPath", "parse_error", err, "path", path)
+			return nil, fmt.Errorf("failed to unmarshal file content %s: %v", path, err)
+		}
+		d.StreamListItem(ctx, parseJsonnetContent{path, result})
+	}
+	return nil, nil
+}
+
+// evaluateJsonnet evaluates a Jsonnet file with the configured library paths
+// and external and top-level arguments, and returns the JSON output.
+func evaluateJsonnet(d *plugin.QueryData, path string) ([]byte, error) {
+	vm := jsonnet.MakeVM()
+	if o := GetConfig(d.Connection).JsonnetOptions; o != nil {
+		vm.Importer(&jsonnet.FileImporter{JPaths: o.LibraryPaths})
+		for k, v := range o.ExtVars {
+			vm.ExtVar(k, v)
+		}
+		for k, v := range o.ExtCode {
+			vm.ExtCode(k, v)
+		}
+		for k, v := range o.TLAVars {
+			vm.TLAVar(k, v)
+		}
+		for k, v := range o.TLACode {
+			vm.TLACode(k, v)
+		}
+	}
+	output, err := vm.EvaluateFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(output), nil
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableJsonnetKeyValue(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "jsonnet_key_value",
+		Description: "List all key value pairs of the JSON document produced by evaluating Jsonnet files.",
+		List: &plugin.ListConfig{
+			Hydrate: listJsonnetKeyValue,
+			KeyColumns: plugin.KeyColumnSlice{
+				{
+					Name:    "path",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "include_containers",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: evaluatedKeyValueColumns("Jsonnet"),
+	}
+}
+
+// evaluatedKeyValueColumns returns the columns shared by the key value tables
+// of config languages which are evaluated to a JSON document. Line numbers
+// are not included, since they would refer to the output rather than the
+// source file.
+func evaluatedKeyValueColumns(language string) []*plugin.Column {
+	return []*plugin.Column{
+		{Name: "path", Type: proto.ColumnType_STRING, Description: fmt.Sprintf("Specifies the path of the %s file.", language)},
+		{Name: "key_path", Type: proto.ColumnType_LTREE, Transform: transform.FromField("Key").Transform(keysToSnakeCase), Description: "Specifies full path of a key in the evaluated document."},
+		{Name: "value", Type: proto.ColumnType_STRING, Description: "Specifies the value of the corresponding key."},
+		{Name: "value_number", Type: proto.ColumnType_DOUBLE, Transform: transform.FromField("ValueNumber"), Description: "The value of the corresponding key, if it is a number."},
+		{Name: "value_bool", Type: proto.ColumnType_BOOL, Transform: transform.FromField("ValueBool"), Description: "The value of the corresponding key, if it is a boolean."},
+		{Name: "value_timestamp", Type: proto.ColumnType_TIMESTAMP, Transform: transform.FromField("ValueTimestamp"), Description: "The value of the corresponding key, if it is a string holding a timestamp or date."},
+		{Name: "value_json", Type: proto.ColumnType_JSON, Transform: transform.FromField("ValueJSON"), Description: "The value of the corresponding key as a typed JSON value."},
+		{Name: "keys", Type: proto.ColumnType_JSON, Transform: transform.FromField("Key"), Description: "The array representation of path of a key."},
+		{Name: "node_kind", Type: proto.ColumnType_STRING, Description: "The kind of the node, i.e. scalar, mapping or sequence."},
+		{Name: "child_count", Type: proto.ColumnType_INT, Transform: transform.FromField("ChildCount"), Description: "The number of entries in a mapping or sequence."},
+		{Name: "include_containers", Type: proto.ColumnType_BOOL, Transform: transform.FromQual("include_containers"), Description: "If true, rows are also returned for non-empty objects and arrays, with the subtree in value_json. Defaults to false."},
+	}
+}
+
+func listJsonnetKeyValue(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// #1 - Path via qual
+	// If the path was requested through qualifier then match it exactly. Globs
+	// are not supported in this context since the output value for the column
+	// will never match the requested value.
+	//
+	// #2 - Path via glob paths in config
+	var paths []string
+	if d.EqualsQuals["path"] != nil {
+		paths = []string{d.EqualsQuals["path"].GetStringValue()}
+	} else {
+		var err error
+		paths, err = listJsonnetFiles(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	includeContainers := d.EqualsQuals["include_containers"] != nil && d.EqualsQuals["include_containers"].GetBoolValue()
+	for _, path := range paths {
+		output, err := evaluateJsonnet(d, path)
+		if err != nil {
+			plugin.Logger(ctx).Error("jsonnet_key_value.listJsonnetKeyValue", "evaluation_error", err, "path", path)
+			return nil, fmt.Errorf("failed to evaluate file %s: %v", path, err)
+		}
+		rows, err := flattenEvaluatedJSON(output, includeContainers)
+		if err != nil {
+			plugin.Logger(ctx).Error("jsonnet_key_value.listJsonnetKeyValue", "parse_error", err, "path", path)
+			return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
+		}
+		for _, r := range rows {
+			r.Path = path
+			d.StreamListItem(ctx, r)
+		}
+	}
+	return nil, nil
+}
+
+// flattenEvaluatedJSON flattens the JSON output of an evaluated config file
+// into the same rows as the json_key_value table.
+func flattenEvaluatedJSON(output []byte, includeContainers bool) (Rows, error) {
+	root, err := parseJSONNode(output, jsonDialectStrict)
+	if err != nil {
+		return nil, err
+	}
+	var rows Rows
+	treeToList(root, []string{}, &rows, nil, nil, nil, includeContainers)
+	return rows, nil
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	"gopkg.in/yaml.v3"
+)
+
+func tableKubernetesManifest(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "kubernetes_manifest",
+		Description: "List all Kubernetes resources defined in manifest files, one row per document.",
+		List: &plugin.ListConfig{
+			Hydrate: listKubernetesManifests,
+			KeyColumns: plugin.KeyColumnSlice{
+				{
+					Name:    "path",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{Name: "path", Type: proto.ColumnType_STRING, Description: "Specifies the path of the manifest file."},
+			{Name: "document_index", Type: proto.ColumnType_INT, Transform: transform.FromField("DocumentIndex"), Description: "The zero-based index of the document in a multi-document file."},
+			{Name: "item_index", Type: proto.ColumnType_INT, Transform: transform.FromField("ItemIndex"), Description: "The zero-based index of the resource in the items of a List document. Not set for resources which are not in a List."},
+			{Name: "api_version", Type: proto.ColumnType_STRING, Transform: transform.FromField("APIVersion"), Description: "The API version of the resource, e.g. apps/v1."},
+			{Name: "kind", Type: proto.ColumnType_STRING, Description: "The kind of the resource, e.g. Deployment."},
+			{Name: "name", Type: proto.ColumnType_STRING, Description: "The name of the resource."},
+			{Name: "namespace", Type: proto.ColumnType_STRING, Description: "The namespace of the resource, if set in the manifest."},
+			{Name: "labels", Type: proto.ColumnType_JSON, Description: "The labels of the resource."},
+			{Name: "annotations", Type: proto.ColumnType_JSON, Description: "The annotations of the resource."},
+			{Name: "spec", Type: proto.ColumnType_JSON, Description: "The spec of the resource."},
+			{Name: "content", Type: proto.ColumnType_JSON, Description: "The full content of the resource."},
+			{Name: "start_line", Type: proto.ColumnType_INT, Description: "The line number where the resource starts."},
+		},
+	}
+}
+
+type kubernetesManifest struct {
+	Path          string
+	DocumentIndex int
+	ItemIndex     *int
+	APIVersion    interface{}
+	Kind          interface{}
+	Name          interface{}
+	Namespace     interface{}
+	Labels        interface{}
+	Annotations   interface{}
+	Spec          interface{}
+	Content       interface{}
+	StartLine     int
+}
+
+func listKubernetesManifests(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// #1 - Path via qual
+	// If the path was requested through qualifier then match it exactly. Globs
+	// are not supported in this context since the output value for the column
+	// will never match the requested value.
+	//
+	// #2 - Path via glob paths in config
+	var paths []string
+	if d.EqualsQuals["path"] != nil {
+		paths = []string{d.EqualsQuals["path"].GetStringValue()}
+	} else {
+		var err error
+		paths, err = listKubernetesFiles(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range paths {
+		reader, err := os.Open(path)
+		if err != nil {
+			plugin.Logger(ctx).Error("kubernetes_manifest.listKubernetesManifests", "file_error", err, "path", path)
+			return nil, fmt.Errorf("failed to read file %s: %v", path, err)
+		}
+
+		// Decode each document of a multi-document file in turn
+		decoder := yaml.NewDecoder(reader)
+		for index := 0; ; index++ {
+			var doc yaml.Node
+			err := decoder.Decode(&doc)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				reader.Close()
+				plugin.Logger(ctx).Error("kubernetes_manifest.listKubernetesManifests", "parse_error", err, "path", path)
+				return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
+			}
+
+			// Skip empty documents, e.g. a trailing ---
+			if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+				continue
+			}
+			root := doc.Content[0]
+
+			manifest := newKubernetesManifest(path, index, root)
+			kind, _ := manifest.Kind.(string)
+			items := mappingValue(root, "items")
+			if !strings.HasSuffix(kind, "List") || items == nil || items.Kind != yaml.SequenceNode {
+				d.StreamListItem(ctx, manifest)
+				continue
+			}
+
+			// Expand lists, e.g. the output of kubectl get -o yaml, into their items
+			for i, item := range items.Content {
+				if item.Kind != yaml.MappingNode {
+					continue
+				}
+				itemIndex := i
+				manifest := newKubernetesManifest(path, index, item)
+				manifest.ItemIndex = &itemIndex
+				d.StreamListItem(ctx, manifest)
+			}
+		}
+		reader.Close()
+	}
+	return nil, nil
+}
+
+func newKubernetesManifest(path string, index int, node *yaml.Node) kubernetesManifest {
+	manifest := kubernetesManifest{
+		Path:          path,
+		DocumentIndex: index,
+		Content:       nodeToJSON(node),
+		StartLine:     node.Line,
+	}
+	content, _ := manifest.Content.(map[string]interface{})
+	manifest.APIVersion = content["apiVersion"]
+	manifest.Kind = content["kind"]
+	manifest.Spec = content["spec"]
+	if metadata, ok := content["metadata"].(map[string]interface{}); ok {
+		manifest.Name = metadata["name"]
+		manifest.Namespace = metadata["namespace"]
+		manifest.Labels = metadata["labels"]
+		manifest.Annotations = metadata["annotations"]
+	}
+	return manifest
+}
+
+// mappingValue returns the value node of a key in a mapping node, or nil if
+// the key is not found.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
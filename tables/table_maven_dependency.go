@@ -0,0 +1,522 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableMavenDependency(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "maven_dependency",
+		Description: "List the dependencies declared in Maven POM files, with versions resolved from properties, parent POMs and dependency management.",
+		List: &plugin.ListConfig{
+			Hydrate: listMavenDependency,
+			KeyColumns: plugin.KeyColumnSlice{
+				{
+					Name:    "path",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{Name: "path", Type: proto.ColumnType_STRING, Description: "Specifies the path of the POM file."},
+			{Name: "project_group_id", Type: proto.ColumnType_STRING, Description: "The group ID of the project, inherited from its parent if not set."},
+			{Name: "project_artifact_id", Type: proto.ColumnType_STRING, Description: "The artifact ID of the project."},
+			{Name: "project_version", Type: proto.ColumnType_STRING, Description: "The version of the project, inherited from its parent if not set."},
+			{Name: "section", Type: proto.ColumnType_STRING, Description: "The section the dependency is declared in, i.e. dependencies or dependency_management."},
+			{Name: "group_id", Type: proto.ColumnType_STRING, Description: "The group ID of the dependency, with properties resolved."},
+			{Name: "artifact_id", Type: proto.ColumnType_STRING, Description: "The artifact ID of the dependency, with properties resolved."},
+			{Name: "version", Type: proto.ColumnType_STRING, Description: "The version of the dependency as declared in the file, e.g. ${spring.version}."},
+			{Name: "resolved_version", Type: proto.ColumnType_STRING, Description: "The version of the dependency with properties resolved, or the managed version if none is declared. Unresolved properties are kept as-is."},
+			{Name: "version_source", Type: proto.ColumnType_STRING, Description: "Where the resolved version comes from, i.e. declared or dependency_management."},
+			{Name: "type", Type: proto.ColumnType_STRING, Description: "The type of the dependency. Defaults to jar."},
+			{Name: "classifier", Type: proto.ColumnType_STRING, Description: "The classifier of the dependency."},
+			{Name: "scope", Type: proto.ColumnType_STRING, Description: "The scope of the dependency, from the file or dependency management. Defaults to compile for dependencies."},
+			{Name: "optional", Type: proto.ColumnType_BOOL, Transform: transform.FromField("Optional"), Description: "True if the dependency is optional."},
+			{Name: "line", Type: proto.ColumnType_INT, Description: "The line of the dependency element in the file."},
+		},
+	}
+}
+
+type mavenDependencyRow struct {
+	Path              string
+	ProjectGroupID    string
+	ProjectArtifactID string
+	ProjectVersion    string
+	Section           string
+	GroupID           string
+	ArtifactID        string
+	Version           string
+	ResolvedVersion   string
+	VersionSource     string
+	Type              string
+	Classifier        string
+	Scope             string
+	Optional          bool
+	Line              int
+}
+
+func listMavenDependency(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// #1 - Path via qual
+	// If the path was requested through qualifier then match it exactly. Globs
+	// are not supported in this context since the output value for the column
+	// will never match the requested value.
+	//
+	// #2 - Path via glob paths in config
+	var paths []string
+	scanned, err := listMavenFiles(ctx, d)
+	if d.EqualsQuals["path"] != nil {
+		paths = []string{d.EqualsQuals["path"].GetStringValue()}
+	} else {
+		if err != nil {
+			return nil, err
+		}
+		paths = scanned
+	}
+
+	// All scanned POMs are candidates for parents and imported BOMs, even when
+	// a single file is queried
+	r := newMavenResolver(GetConfig(d.Connection).XMLOptions)
+	for _, path := range scanned {
+		if _, err := r.load(path); err != nil {
+			plugin.Logger(ctx).Warn("maven_dependency.listMavenDependency", "parse_error", err, "path", path)
+		}
+	}
+
+	for _, path := range paths {
+		pom, err := r.load(path)
+		if err != nil {
+			plugin.Logger(ctx).Error("maven_dependency.listMavenDependency", "parse_error", err, "path", path)
+			return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
+		}
+		for _, row := range r.dependencies(pom) {
+			d.StreamListItem(ctx, row)
+		}
+	}
+	return nil, nil
+}
+
+// mavenPOM is the part of a POM file which is needed to resolve the versions
+// of its dependencies.
+type mavenPOM struct {
+	Path                 string
+	GroupID              string
+	ArtifactID           string
+	Version              string
+	Parent               *mavenParent
+	Properties           map[string]string
+	Dependencies         []mavenDependency
+	DependencyManagement []mavenDependency
+}
+
+type mavenParent struct {
+	GroupID      string
+	ArtifactID   string
+	Version      string
+	RelativePath *string
+}
+
+type mavenDependency struct {
+	GroupID    string
+	ArtifactID string
+	Version    string
+	Type       string
+	Classifier string
+	Scope      string
+	Optional   string
+	Line       int
+}
+
+// parseMavenPOM reads the project coordinates, parent, properties and
+// dependencies of a POM file. Dependencies of profiles and plugins are not
+// included.
+func parseMavenPOM(path string, content []byte, o *xmlOptions) (*mavenPOM, error) {
+	doc, err := parseXMLDocument(content, o)
+	if err != nil {
+		return nil, err
+	}
+	project := xmlChildElement(doc, "project")
+	if project == nil {
+		return nil, fmt.Errorf("missing project element")
+	}
+
+	pom := &mavenPOM{
+		Path:       path,
+		GroupID:    xmlChildText(project, "groupId"),
+		ArtifactID: xmlChildText(project, "artifactId"),
+		Version:    xmlChildText(project, "version"),
+		Properties: map[string]string{},
+	}
+	if parent := xmlChildElement(project, "parent"); parent != nil {
+		pom.Parent = &mavenParent{
+			GroupID:    xmlChildText(parent, "groupId"),
+			ArtifactID: xmlChildText(parent, "artifactId"),
+			Version:    xmlChildText(parent, "version"),
+		}
+		if rel := xmlChildElement(parent, "relativePath"); rel != nil {
+			s := strings.TrimSpace(rel.InnerText())
+			pom.Parent.RelativePath = &s
+		}
+	}
+	if properties := xmlChildElement(project, "properties"); properties != nil {
+		for n := properties.FirstChild; n != nil; n = n.NextSibling {
+			if n.Type == xmlquery.ElementNode {
+				pom.Properties[n.Data] = strings.TrimSpace(n.InnerText())
+			}
+		}
+	}
+	pom.Dependencies = parseMavenDependencies(xmlChildElement(project, "dependencies"))
+	if management := xmlChildElement(project, "dependencyManagement"); management != nil {
+		pom.DependencyManagement = parseMavenDependencies(xmlChildElement(management, "dependencies"))
+	}
+	return pom, nil
+}
+
+func parseMavenDependencies(dependencies *xmlquery.Node) []mavenDependency {
+	if dependencies == nil {
+		return nil
+	}
+	var result []mavenDependency
+	for n := dependencies.FirstChild; n != nil; n = n.NextSibling {
+		if n.Type != xmlquery.ElementNode || n.Data != "dependency" {
+			continue
+		}
+		result = append(result, mavenDependency{
+			GroupID:    xmlChildText(n, "groupId"),
+			ArtifactID: xmlChildText(n, "artifactId"),
+			Version:    xmlChildText(n, "version"),
+			Type:       xmlChildText(n, "type"),
+			Classifier: xmlChildText(n, "classifier"),
+			Scope:      xmlChildText(n, "scope"),
+			Optional:   xmlChildText(n, "optional"),
+			Line:       n.LineNumber,
+		})
+	}
+	return result
+}
+
+// xmlChildElement returns the first child element of a node with the given
+// local name.
+func xmlChildElement(n *xmlquery.Node, name string) *xmlquery.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == xmlquery.ElementNode && c.Data == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// xmlChildText returns the trimmed text of the first child element of a node
+// with the given local name.
+func xmlChildText(n *xmlquery.Node, name string) string {
+	if c := xmlChildElement(n, name); c != nil {
+		return strings.TrimSpace(c.InnerText())
+	}
+	return ""
+}
+
+// mavenMaxParents is the maximum length of a chain of parent POMs, which
+// guards against cycles.
+const mavenMaxParents = 32
+
+// mavenResolver resolves the parents and imported BOMs of the POM files of a
+// query. Parents are found by their relative path, which defaults to
+// ../pom.xml, or else by their coordinates among the scanned POM files.
+// Repositories are never queried.
+type mavenResolver struct {
+	options *xmlOptions
+	poms    map[string]*mavenPOM
+	errors  map[string]error
+	byGAV   map[string]*mavenPOM
+	byGA    map[string]*mavenPOM
+}
+
+func newMavenResolver(o *xmlOptions) *mavenResolver {
+	return &mavenResolver{
+		options: o,
+		poms:    map[string]*mavenPOM{},
+		errors:  map[string]error{},
+		byGAV:   map[string]*mavenPOM{},
+		byGA:    map[string]*mavenPOM{},
+	}
+}
+
+// load parses a POM file once, and indexes it by its coordinates.
+func (r *mavenResolver) load(path string) (*mavenPOM, error) {
+	if pom, ok := r.poms[path]; ok {
+		return pom, r.errors[path]
+	}
+	content, err := os.ReadFile(path)
+	var pom *mavenPOM
+	if err == nil {
+		pom, err = parseMavenPOM(path, content, r.options)
+	}
+	r.poms[path] = pom
+	r.errors[path] = err
+	if err != nil {
+		return nil, err
+	}
+
+	groupID, version := pom.GroupID, pom.Version
+	if pom.Parent != nil {
+		if groupID == "" {
+			groupID = pom.Parent.GroupID
+		}
+		if version == "" {
+			version = pom.Parent.Version
+		}
+	}
+	r.byGAV[groupID+":"+pom.ArtifactID+":"+version] = pom
+	r.byGA[groupID+":"+pom.ArtifactID] = pom
+	return pom, nil
+}
+
+// parent returns the parent POM of a POM, or nil if it has none or it cannot
+// be found.
+func (r *mavenResolver) parent(pom *mavenPOM) *mavenPOM {
+	p := pom.Parent
+	if p == nil {
+		return nil
+	}
+	rel := "../pom.xml"
+	if p.RelativePath != nil {
+		rel = *p.RelativePath
+	}
+	if rel != "" {
+		path := filepath.Join(filepath.Dir(pom.Path), filepath.FromSlash(rel))
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			path = filepath.Join(path, "pom.xml")
+		}
+		if parent, err := r.load(path); err == nil && parent.ArtifactID == p.ArtifactID {
+			return parent
+		}
+	}
+	if parent := r.byGAV[p.GroupID+":"+p.ArtifactID+":"+p.Version]; parent != nil {
+		return parent
+	}
+	return r.byGA[p.GroupID+":"+p.ArtifactID]
+}
+
+// lineage returns a POM and its ancestors, starting with the root ancestor.
+func (r *mavenResolver) lineage(pom *mavenPOM) []*mavenPOM {
+	lineage := []*mavenPOM{pom}
+	seen := map[*mavenPOM]bool{pom: true}
+	for p := r.parent(pom); p != nil && !seen[p] && len(lineage) < mavenMaxParents; p = r.parent(p) {
+		seen[p] = true
+		lineage = append([]*mavenPOM{p}, lineage...)
+	}
+	return lineage
+}
+
+// mavenProject is a POM with its inherited coordinates, properties and
+// dependency management.
+type mavenProject struct {
+	GroupID    string
+	ArtifactID string
+	Version    string
+	Properties map[string]string
+	Managed    map[string]mavenDependency
+
+	// resolved caches the interpolated values of properties
+	resolved map[string]string
+}
+
+// effective returns the project of a POM with the coordinates, properties and
+// dependency management inherited from its ancestors. Managed dependencies
+// with the import scope are replaced by the dependency management of the
+// imported BOM, if it is one of the scanned POM files.
+func (r *mavenResolver) effective(pom *mavenPOM, importing map[*mavenPOM]bool) *mavenProject {
+	project := &mavenProject{Properties: map[string]string{}, Managed: map[string]mavenDependency{}}
+	lineage := r.lineage(pom)
+	for _, p := range lineage {
+		if p.GroupID != "" {
+			project.GroupID = p.GroupID
+		} else if p.Parent != nil && p.Parent.GroupID != "" {
+			project.GroupID = p.Parent.GroupID
+		}
+		if p.Version != "" {
+			project.Version = p.Version
+		} else if p.Parent != nil && p.Parent.Version != "" {
+			project.Version = p.Parent.Version
+		}
+		project.ArtifactID = p.ArtifactID
+		for k, v := range p.Properties {
+			project.Properties[k] = v
+		}
+	}
+	for _, prefix := range []string{"project.", "pom.", ""} {
+		project.Properties[prefix+"groupId"] = project.GroupID
+		project.Properties[prefix+"artifactId"] = project.ArtifactID
+		project.Properties[prefix+"version"] = project.Version
+	}
+	if pom.Parent != nil {
+		project.Properties["project.parent.groupId"] = pom.Parent.GroupID
+		project.Properties["project.parent.artifactId"] = pom.Parent.ArtifactID
+		project.Properties["project.parent.version"] = pom.Parent.Version
+	}
+	project.Properties["project.basedir"] = filepath.Dir(pom.Path)
+	project.Properties["basedir"] = filepath.Dir(pom.Path)
+
+	importing[pom] = true
+	defer delete(importing, pom)
+	for _, p := range lineage {
+		for _, m := range p.DependencyManagement {
+			m = project.interpolateDependency(m)
+			if m.Scope == "import" && m.Type == "pom" {
+				bom := r.byGAV[m.GroupID+":"+m.ArtifactID+":"+m.Version]
+				if bom == nil {
+					bom = r.byGA[m.GroupID+":"+m.ArtifactID]
+				}
+				if bom != nil && !importing[bom] {
+					for k, v := range r.effective(bom, importing).Managed {
+						if _, ok := project.Managed[k]; !ok {
+							project.Managed[k] = v
+						}
+					}
+					continue
+				}
+			}
+			project.Managed[m.managementKey()] = m
+		}
+	}
+	return project
+}
+
+// dependencies returns the rows of the dependencies and managed dependencies
+// declared in a POM.
+func (r *mavenResolver) dependencies(pom *mavenPOM) []mavenDependencyRow {
+	project := r.effective(pom, map[*mavenPOM]bool{})
+	var rows []mavenDependencyRow
+	row := func(section string, dep mavenDependency) mavenDependencyRow {
+		resolved := project.interpolateDependency(dep)
+		result := mavenDependencyRow{
+			Path:              pom.Path,
+			ProjectGroupID:    project.interpolate(project.GroupID),
+			ProjectArtifactID: project.interpolate(project.ArtifactID),
+			ProjectVersion:    project.interpolate(project.Version),
+			Section:           section,
+			GroupID:           resolved.GroupID,
+			ArtifactID:        resolved.ArtifactID,
+			Version:           dep.Version,
+			ResolvedVersion:   resolved.Version,
+			Type:              resolved.Type,
+			Classifier:        resolved.Classifier,
+			Scope:             resolved.Scope,
+			Optional:          resolved.Optional == "true",
+			Line:              dep.Line,
+		}
+		if resolved.Version != "" {
+			result.VersionSource = "declared"
+		}
+		if section == "dependencies" {
+			if managed, ok := project.Managed[resolved.managementKey()]; ok {
+				if result.ResolvedVersion == "" && managed.Version != "" {
+					result.ResolvedVersion = managed.Version
+					result.VersionSource = "dependency_management"
+				}
+				if result.Scope == "" {
+					result.Scope = managed.Scope
+				}
+				if resolved.Optional == "" {
+					result.Optional = managed.Optional == "true"
+				}
+			}
+			if result.Scope == "" {
+				result.Scope = "compile"
+			}
+		}
+		return result
+	}
+	for _, dep := range pom.Dependencies {
+		rows = append(rows, row("dependencies", dep))
+	}
+	for _, dep := range pom.DependencyManagement {
+		rows = append(rows, row("dependency_management", dep))
+	}
+	return rows
+}
+
+// managementKey returns the key matching a dependency to its managed version.
+func (dep mavenDependency) managementKey() string {
+	return strings.Join([]string{dep.GroupID, dep.ArtifactID, dep.Type, dep.Classifier}, ":")
+}
+
+// interpolateDependency resolves the properties in the coordinates of a
+// dependency, and defaults its type to jar.
+func (p *mavenProject) interpolateDependency(dep mavenDependency) mavenDependency {
+	dep.GroupID = p.interpolate(dep.GroupID)
+	dep.ArtifactID = p.interpolate(dep.ArtifactID)
+	dep.Version = p.interpolate(dep.Version)
+	dep.Type = p.interpolate(dep.Type)
+	dep.Classifier = p.interpolate(dep.Classifier)
+	dep.Scope = p.interpolate(dep.Scope)
+	dep.Optional = p.interpolate(dep.Optional)
+	if dep.Type == "" {
+		dep.Type = "jar"
+	}
+	return dep
+}
+
+var mavenPropertyRegex = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// mavenMaxValueLength bounds the length of interpolated values, since
+// properties referring several times to other properties grow exponentially.
+const mavenMaxValueLength = 64 << 10
+
+// interpolate resolves ${property} references in a value, including
+// references in the values of properties. Unknown properties, references
+// which are part of a cycle, and references which would make the value longer
+// than mavenMaxValueLength are kept as-is.
+func (p *mavenProject) interpolate(value string) string {
+	return p.expand(value, map[string]bool{})
+}
+
+func (p *mavenProject) expand(value string, visiting map[string]bool) string {
+	if !strings.Contains(value, "${") {
+		return value
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range mavenPropertyRegex.FindAllStringSubmatchIndex(value, -1) {
+		b.WriteString(value[last:m[0]])
+		ref := value[m[0]:m[1]]
+		if v := p.property(value[m[2]:m[3]], ref, visiting); b.Len()+len(v)+len(value)-m[1] <= mavenMaxValueLength {
+			b.WriteString(v)
+		} else {
+			b.WriteString(ref)
+		}
+		last = m[1]
+	}
+	b.WriteString(value[last:])
+	return b.String()
+}
+
+// property returns the interpolated value of a property, or its reference if
+// the property is unknown or is being resolved, i.e. is part of a cycle. Each
+// property is resolved once.
+func (p *mavenProject) property(name string, ref string, visiting map[string]bool) string {
+	if v, ok := p.resolved[name]; ok {
+		return v
+	}
+	raw, ok := p.Properties[name]
+	if !ok || visiting[name] {
+		return ref
+	}
+	visiting[name] = true
+	v := p.expand(raw, visiting)
+	delete(visiting, name)
+	if p.resolved == nil {
+		p.resolved = map[string]string{}
+	}
+	p.resolved[name] = v
+	return v
+}
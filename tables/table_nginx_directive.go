@@ -0,0 +1,425 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableNginxDirective(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "nginx_directive",
+		Description: "List the directives of nginx config files, including the directives of included files.",
+		List: &plugin.ListConfig{
+			Hydrate: listNginxDirectives,
+			KeyColumns: plugin.KeyColumnSlice{
+				{
+					Name:    "path",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: directiveColumns(),
+	}
+}
+
+// directiveColumns returns the columns shared by the tables of web server
+// config files with nested blocks of directives.
+func directiveColumns() []*plugin.Column {
+	return []*plugin.Column{
+		{Name: "path", Type: proto.ColumnType_STRING, Description: "Specifies the path of the main config file."},
+		{Name: "source_path", Type: proto.ColumnType_STRING, Description: "The path of the file the directive is defined in, which differs from path for directives in included files."},
+		{Name: "block_path", Type: proto.ColumnType_STRING, Description: "The path of the block the directive is in, e.g. http.server[1].location[/api]. Empty for top level directives."},
+		{Name: "directive", Type: proto.ColumnType_STRING, Description: "The name of the directive."},
+		{Name: "args", Type: proto.ColumnType_JSON, Description: "The arguments of the directive, with quotes removed."},
+		{Name: "is_block", Type: proto.ColumnType_BOOL, Transform: transform.FromField("IsBlock"), Description: "True if the directive opens a block of nested directives."},
+		{Name: "line", Type: proto.ColumnType_INT, Description: "The line number of the directive in source_path."},
+	}
+}
+
+type directiveRow struct {
+	Path       string
+	SourcePath string
+	BlockPath  string
+	Directive  string
+	Args       []string
+	IsBlock    bool
+	Line       int
+}
+
+func listNginxDirectives(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// #1 - Path via qual
+	// If the path was requested through qualifier then match it exactly. Globs
+	// are not supported in this context since the output value for the column
+	// will never match the requested value.
+	//
+	// #2 - Path via glob paths in config
+	var paths []string
+	if d.EqualsQuals["path"] != nil {
+		paths = []string{d.EqualsQuals["path"].GetStringValue()}
+	} else {
+		var err error
+		paths, err = listNginxFiles(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range paths {
+		l := directiveLoader{
+			root:  filepath.Dir(path),
+			parse: parseNginxConfig,
+			isInclude: func(directive string) bool {
+				return directive == "include"
+			},
+		}
+		nodes, err := l.load(path, 0)
+		if err != nil {
+			plugin.Logger(ctx).Error("nginx_directive.listNginxDirectives", "parse_error", err, "path", path)
+			return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
+		}
+		var rows []directiveRow
+		flattenDirectives(nodes, "", &rows)
+		for _, row := range rows {
+			row.Path = path
+			d.StreamListItem(ctx, row)
+		}
+	}
+	return nil, nil
+}
+
+// directiveNode is a directive of a config file, with its nested directives
+// if it opens a block.
+type directiveNode struct {
+	Directive  string
+	Args       []string
+	Block      bool
+	Children   []*directiveNode
+	SourcePath string
+	Line       int
+}
+
+// directiveMaxIncludeDepth is the maximum depth of nested include directives.
+const directiveMaxIncludeDepth = 16
+
+// directiveLoader parses config files with nested blocks of directives, and
+// follows their include directives. Relative include patterns are resolved
+// against the config root, i.e. the directory of the main config file or the
+// one set by rootDirective.
+type directiveLoader struct {
+	root      string
+	parse     func(path string, content string) ([]*directiveNode, error)
+	isInclude func(directive string) bool
+
+	// rootDirective is the directive setting the config root, i.e. ServerRoot
+	// for Apache httpd, or empty if the root is the directory of the main
+	// config file
+	rootDirective string
+	// includeDirectories is true if including a directory includes the files
+	// in it and in its subdirectories, in alphabetical order, as in Apache
+	// httpd. Otherwise directories are skipped.
+	includeDirectories bool
+
+	// including holds the files on the current include stack
+	including map[string]bool
+}
+
+// load parses a file and the files it includes. Files which include
+// themselves, directly or through other files, are rejected, as the web server
+// would never finish loading them.
+func (l *directiveLoader) load(path string, depth int) ([]*directiveNode, error) {
+	if depth > directiveMaxIncludeDepth {
+		return nil, fmt.Errorf("too many nested includes at %s", path)
+	}
+	id, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if l.including[id] {
+		return nil, fmt.Errorf("%s includes itself", path)
+	}
+	if l.including == nil {
+		l.including = map[string]bool{}
+	}
+	l.including[id] = true
+	defer delete(l.including, id)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := l.parse(path, string(content))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return l.expand(nodes, depth)
+}
+
+// expand returns the nodes with the directives of included files inserted
+// after each include directive, in the block of the include directive.
+// Patterns which match no files are ignored.
+func (l *directiveLoader) expand(nodes []*directiveNode, depth int) ([]*directiveNode, error) {
+	var result []*directiveNode
+	for _, node := range nodes {
+		if node.Block {
+			children, err := l.expand(node.Children, depth)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = children
+		}
+		result = append(result, node)
+		// A root which does not exist, e.g. in a config exported from another
+		// host, is ignored so includes still resolve against the default root
+		if l.rootDirective != "" && strings.EqualFold(node.Directive, l.rootDirective) && len(node.Args) > 0 {
+			root := node.Args[0]
+			if !filepath.IsAbs(root) {
+				root = filepath.Join(l.root, root)
+			}
+			if isDir(root) {
+				l.root = root
+			}
+			continue
+		}
+		if !l.isInclude(node.Directive) {
+			continue
+		}
+		for _, pattern := range node.Args {
+			if !filepath.IsAbs(pattern) {
+				pattern = filepath.Join(l.root, pattern)
+			}
+			matches, err := filepath.Glob(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid include pattern %q: %v", pattern, err)
+			}
+			sort.Strings(matches)
+			for _, i := range matches {
+				info, err := os.Stat(i)
+				if err != nil || (info.IsDir() && !l.includeDirectories) {
+					continue
+				}
+				files := []string{i}
+				if info.IsDir() {
+					if files, err = directoryFiles(i); err != nil {
+						return nil, err
+					}
+				}
+				for _, f := range files {
+					included, err := l.load(f, depth+1)
+					if err != nil {
+						return nil, err
+					}
+					result = append(result, included...)
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// directoryFiles returns the files in a directory and its subdirectories, in
+// alphabetical order.
+func directoryFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type().IsRegular() || (entry.Type()&fs.ModeSymlink != 0 && !isDir(path)) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// flattenDirectives appends a row for each directive of a tree. Blocks with
+// arguments are identified by them in the block path, e.g. location[/api],
+// while blocks without arguments are identified by their zero-based index
+// among the blocks of the same name, e.g. server[1], if there is more than
+// one.
+func flattenDirectives(nodes []*directiveNode, blockPath string, rows *[]directiveRow) {
+	counts := map[string]int{}
+	for _, node := range nodes {
+		if node.Block && len(node.Args) == 0 {
+			counts[strings.ToLower(node.Directive)]++
+		}
+	}
+
+	indexes := map[string]int{}
+	for _, node := range nodes {
+		*rows = append(*rows, directiveRow{
+			SourcePath: node.SourcePath,
+			BlockPath:  blockPath,
+			Directive:  node.Directive,
+			Args:       node.Args,
+			IsBlock:    node.Block,
+			Line:       node.Line,
+		})
+		if !node.Block {
+			continue
+		}
+
+		segment := node.Directive
+		name := strings.ToLower(node.Directive)
+		switch {
+		case len(node.Args) > 0:
+			segment += "[" + strings.Join(node.Args, " ") + "]"
+		case counts[name] > 1:
+			segment += fmt.Sprintf("[%d]", indexes[name])
+			indexes[name]++
+		}
+		if blockPath != "" {
+			segment = blockPath + "." + segment
+		}
+		flattenDirectives(node.Children, segment, rows)
+	}
+}
+
+type nginxToken struct {
+	value  string
+	line   int
+	quoted bool
+}
+
+// parseNginxConfig parses the directives of an nginx config file. Directives
+// end with ; or open a block with {, which is closed by }.
+func parseNginxConfig(path string, content string) ([]*directiveNode, error) {
+	tokens, err := tokenizeNginxConfig(content)
+	if err != nil {
+		return nil, err
+	}
+	nodes, pos, err := parseNginxBlock(path, tokens, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	if pos < len(tokens) {
+		return nil, fmt.Errorf("unexpected } on line %d", tokens[pos].line)
+	}
+	return nodes, nil
+}
+
+func parseNginxBlock(path string, tokens []nginxToken, pos int, nested bool) ([]*directiveNode, int, error) {
+	var nodes []*directiveNode
+	var words []nginxToken
+	for pos < len(tokens) {
+		t := tokens[pos]
+		pos++
+		if t.quoted {
+			words = append(words, t)
+			continue
+		}
+		switch t.value {
+		case ";", "{":
+			if len(words) == 0 {
+				return nil, pos, fmt.Errorf("unexpected %s on line %d", t.value, t.line)
+			}
+			node := &directiveNode{Directive: words[0].value, SourcePath: path, Line: words[0].line}
+			for _, w := range words[1:] {
+				node.Args = append(node.Args, w.value)
+			}
+			words = nil
+			if t.value == "{" {
+				node.Block = true
+				children, next, err := parseNginxBlock(path, tokens, pos, true)
+				if err != nil {
+					return nil, next, err
+				}
+				node.Children, pos = children, next
+			}
+			nodes = append(nodes, node)
+		case "}":
+			if len(words) > 0 {
+				return nil, pos, fmt.Errorf("unexpected } on line %d, expecting ;", t.line)
+			}
+			if !nested {
+				return nodes, pos - 1, nil
+			}
+			return nodes, pos, nil
+		default:
+			words = append(words, t)
+		}
+	}
+	if len(words) > 0 {
+		return nil, pos, fmt.Errorf("unexpected end of file, expecting ; or { after %s on line %d", words[0].value, words[0].line)
+	}
+	if nested {
+		return nil, pos, fmt.Errorf("unexpected end of file, expecting }")
+	}
+	return nodes, pos, nil
+}
+
+var nginxUnescaper = strings.NewReplacer(`\"`, `"`, `\'`, `'`, `\\`, `\`, `\n`, "\n", `\r`, "\r", `\t`, "\t")
+
+// tokenizeNginxConfig splits an nginx config file into words, quoted strings
+// and the ;, { and } special characters, skipping comments.
+func tokenizeNginxConfig(content string) ([]nginxToken, error) {
+	var tokens []nginxToken
+	line := 1
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '\n':
+			line++
+		case c == ' ' || c == '\t' || c == '\r':
+		case c == '#':
+			for i+1 < len(content) && content[i+1] != '\n' {
+				i++
+			}
+		case c == ';' || c == '{' || c == '}':
+			tokens = append(tokens, nginxToken{value: string(c), line: line})
+		case c == '"' || c == '\'':
+			start, startLine := i+1, line
+			i++
+			for i < len(content) && content[i] != c {
+				if content[i] == '\\' {
+					i++
+				} else if content[i] == '\n' {
+					line++
+				}
+				i++
+			}
+			if i >= len(content) {
+				return nil, fmt.Errorf("unterminated string starting on line %d", startLine)
+			}
+			tokens = append(tokens, nginxToken{value: nginxUnescaper.Replace(content[start:i]), line: startLine, quoted: true})
+		default:
+			start := i
+			for i < len(content) {
+				c := content[i]
+				if c == '$' && i+1 < len(content) && content[i+1] == '{' {
+					// ${variable} is part of the word
+					end := strings.IndexByte(content[i:], '}')
+					if end < 0 {
+						return nil, fmt.Errorf("unterminated variable on line %d", line)
+					}
+					i += end + 1
+					continue
+				}
+				if c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == ';' || c == '{' || c == '}' {
+					break
+				}
+				if c == '\\' && i+1 < len(content) {
+					i++
+				}
+				i++
+			}
+			tokens = append(tokens, nginxToken{value: content[start:i], line: line})
+			i--
+		}
+	}
+	return tokens, nil
+}
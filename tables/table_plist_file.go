@@ -0,0 +1,144 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"howett.net/plist"
+)
+
+func tablePlistFile(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "plist_file",
+		Description: "Represents the content of Apple property list files, in XML, binary or text form.",
+		List: &plugin.ListConfig{
+			Hydrate: listPlistFileWithPath,
+			KeyColumns: plugin.KeyColumnSlice{
+				{
+					Name:    "path",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{Name: "path", Type: proto.ColumnType_STRING, Description: "Specifies the path of the plist file."},
+			{Name: "format", Type: proto.ColumnType_STRING, Description: "The format of the file, i.e. xml, binary, openstep or gnustep."},
+			{Name: "content", Type: proto.ColumnType_JSON, Description: "Specifies the file content, with dates as RFC 3339 strings and data as base64 strings."},
+		},
+	}
+}
+
+type parsePlistContent struct {
+	Path    string
+	Format  string
+	Content interface{}
+}
+
+func listPlistFileWithPath(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// #1 - Path via qual
+	// If the path was requested through qualifier then match it exactly. Globs
+	// are not supported in this context since the output value for the column
+	// will never match the requested value.
+	//
+	// #2 - Path via glob paths in config
+	var paths []string
+	if d.EqualsQuals["path"] != nil {
+		paths = []string{d.EqualsQuals["path"].GetStringValue()}
+	} else {
+		var err error
+		paths, err = listPlistFiles(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range paths {
+		value, format, err := loadPlist(path)
+		if err != nil {
+			plugin.Logger(ctx).Error("plist_file.listPlistFileWithPath", "parse_error", err, "path", path)
+			return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
+		}
+		d.StreamListItem(ctx, parsePlistContent{path, format, plistToJSON(value)})
+	}
+	return nil, nil
+}
+
+// loadPlist returns the content of a plist file and its format.
+func loadPlist(path string) (interface{}, string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	var value interface{}
+	format, err := plist.Unmarshal(content, &value)
+	if err != nil {
+		return nil, "", err
+	}
+	return value, strings.ToLower(plist.FormatNames[format]), nil
+}
+
+// plistType returns the plist type of a value decoded from a plist file.
+func plistType(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "dict"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case uint64, int64:
+		return "integer"
+	case float64:
+		return "real"
+	case time.Time:
+		return "date"
+	case []byte:
+		return "data"
+	case plist.UID:
+		return "uid"
+	}
+	return ""
+}
+
+// plistToJSON converts a value decoded from a plist file into a JSON value.
+// Dates are converted to RFC 3339 strings, data to base64 strings and UIDs to
+// the {"CF$UID": n} dicts they are written as in XML plists. Infinity and NaN
+// are converted to strings.
+func plistToJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, i := range v {
+			result[key] = plistToJSON(i)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for n, i := range v {
+			result[n] = plistToJSON(i)
+		}
+		return result
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case plist.UID:
+		return map[string]interface{}{"CF$UID": uint64(v)}
+	case float64:
+		// Infinity and NaN have no JSON representation
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return strconv.FormatFloat(v, 'g', -1, 64)
+		}
+	}
+	return value
+}
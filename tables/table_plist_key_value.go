@@ -0,0 +1,174 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	"howett.net/plist"
+)
+
+func tablePlistKeyValue(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "plist_key_value",
+		Description: "List all key value pairs from given Apple property list files.",
+		List: &plugin.ListConfig{
+			Hydrate: listPlistKeyValue,
+			KeyColumns: plugin.KeyColumnSlice{
+				{
+					Name:    "path",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "include_containers",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{Name: "path", Type: proto.ColumnType_STRING, Description: "Specifies the path of the plist file."},
+			{Name: "key_path", Type: proto.ColumnType_LTREE, Transform: transform.FromField("Key").Transform(keysToSnakeCase), Description: "Specifies full path of a key in the plist file."},
+			{Name: "keys", Type: proto.ColumnType_JSON, Transform: transform.FromField("Key"), Description: "The array representation of path of a key."},
+			{Name: "type", Type: proto.ColumnType_STRING, Description: "The plist type of the value, i.e. string, integer, real, boolean, date, data, uid, array or dict."},
+			{Name: "value", Type: proto.ColumnType_STRING, Description: "Specifies the value of the corresponding key, with dates as RFC 3339 strings and data as base64 strings."},
+			{Name: "value_number", Type: proto.ColumnType_DOUBLE, Transform: transform.FromField("ValueNumber"), Description: "The value of the corresponding key, if it is an integer or real."},
+			{Name: "value_bool", Type: proto.ColumnType_BOOL, Transform: transform.FromField("ValueBool"), Description: "The value of the corresponding key, if it is a boolean."},
+			{Name: "value_timestamp", Type: proto.ColumnType_TIMESTAMP, Transform: transform.FromField("ValueTimestamp"), Description: "The value of the corresponding key, if it is a date."},
+			{Name: "value_json", Type: proto.ColumnType_JSON, Transform: transform.FromField("ValueJSON"), Description: "The value of the corresponding key as a typed JSON value."},
+			{Name: "child_count", Type: proto.ColumnType_INT, Transform: transform.FromField("ChildCount"), Description: "The number of entries in a dict or array."},
+			{Name: "include_containers", Type: proto.ColumnType_BOOL, Transform: transform.FromQual("include_containers"), Description: "If true, rows are also returned for non-empty dicts and arrays, with the subtree in value_json. Defaults to false."},
+		},
+	}
+}
+
+type plistKeyValue struct {
+	Path           string
+	Key            []string
+	Type           string
+	Value          *string
+	ValueNumber    *float64
+	ValueBool      *bool
+	ValueTimestamp *time.Time
+	ValueJSON      interface{}
+	ChildCount     int
+}
+
+func listPlistKeyValue(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// #1 - Path via qual
+	// If the path was requested through qualifier then match it exactly. Globs
+	// are not supported in this context since the output value for the column
+	// will never match the requested value.
+	//
+	// #2 - Path via glob paths in config
+	var paths []string
+	if d.EqualsQuals["path"] != nil {
+		paths = []string{d.EqualsQuals["path"].GetStringValue()}
+	} else {
+		var err error
+		paths, err = listPlistFiles(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	includeContainers := d.EqualsQuals["include_containers"] != nil && d.EqualsQuals["include_containers"].GetBoolValue()
+	for _, path := range paths {
+		value, _, err := loadPlist(path)
+		if err != nil {
+			plugin.Logger(ctx).Error("plist_key_value.listPlistKeyValue", "parse_error", err, "path", path)
+			return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
+		}
+
+		var rows []plistKeyValue
+		flattenPlist(value, []string{}, &rows, includeContainers)
+		for _, r := range rows {
+			r.Path = path
+			d.StreamListItem(ctx, r)
+		}
+	}
+	return nil, nil
+}
+
+// flattenPlist flattens a plist value into rows, one per scalar value or
+// empty collection, in order of dict keys. If includeContainers is set, a row
+// is also added for each dict and array, holding its subtree as JSON. It
+// returns the JSON of the value, which is built from the JSON of its children
+// so each subtree is converted once.
+func flattenPlist(value interface{}, keys []string, rows *[]plistKeyValue, includeContainers bool) interface{} {
+	row := plistKeyValue{Key: keys, Type: plistType(value)}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		row.ChildCount = len(v)
+		index := -1
+		if includeContainers || len(v) == 0 {
+			index = len(*rows)
+			*rows = append(*rows, row)
+		}
+		var names []string
+		for i := range v {
+			names = append(names, i)
+		}
+		sort.Strings(names)
+		result := make(map[string]interface{}, len(v))
+		for _, i := range names {
+			result[i] = flattenPlist(v[i], append(append([]string{}, keys...), i), rows, includeContainers)
+		}
+		if index >= 0 {
+			(*rows)[index].ValueJSON = result
+		}
+		return result
+	case []interface{}:
+		row.ChildCount = len(v)
+		index := -1
+		if includeContainers || len(v) == 0 {
+			index = len(*rows)
+			*rows = append(*rows, row)
+		}
+		result := make([]interface{}, len(v))
+		for n, i := range v {
+			result[n] = flattenPlist(i, append(append([]string{}, keys...), strconv.Itoa(n)), rows, includeContainers)
+		}
+		if index >= 0 {
+			(*rows)[index].ValueJSON = result
+		}
+		return result
+	}
+
+	row.ValueJSON = plistToJSON(value)
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case bool:
+		s = strconv.FormatBool(v)
+		row.ValueBool = &v
+	case uint64:
+		s = strconv.FormatUint(v, 10)
+		f := float64(v)
+		row.ValueNumber = &f
+	case int64:
+		s = strconv.FormatInt(v, 10)
+		f := float64(v)
+		row.ValueNumber = &f
+	case float64:
+		s = strconv.FormatFloat(v, 'g', -1, 64)
+		row.ValueNumber = &v
+	case time.Time:
+		s = v.UTC().Format(time.RFC3339)
+		row.ValueTimestamp = &v
+	case []byte:
+		s = base64.StdEncoding.EncodeToString(v)
+	case plist.UID:
+		s = strconv.FormatUint(uint64(v), 10)
+	}
+	row.Value = &s
+	*rows = append(*rows, row)
+	return row.ValueJSON
+}
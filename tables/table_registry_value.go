@@ -0,0 +1,363 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func tableRegistryValue(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "registry_value",
+		Description: "List the values and deletions of Windows registry export (.reg) files.",
+		List: &plugin.ListConfig{
+			Hydrate: listRegistryValues,
+			KeyColumns: plugin.KeyColumnSlice{
+				{
+					Name:    "path",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{Name: "path", Type: proto.ColumnType_STRING, Description: "Specifies the path of the .reg file."},
+			{Name: "hive", Type: proto.ColumnType_STRING, Description: "The root key of the key, e.g. HKEY_LOCAL_MACHINE."},
+			{Name: "key", Type: proto.ColumnType_STRING, Description: "The full path of the registry key, e.g. HKEY_LOCAL_MACHINE\\SOFTWARE\\Policies."},
+			{Name: "value_name", Type: proto.ColumnType_STRING, Transform: transform.FromField("ValueName"), Description: "The name of the value, which is empty for the default value of the key. Null for key deletions."},
+			{Name: "operation", Type: proto.ColumnType_STRING, Description: "The change made by the entry, i.e. set, delete_value or delete_key."},
+			{Name: "value_type", Type: proto.ColumnType_STRING, Description: "The type of the value, e.g. REG_SZ, REG_DWORD, REG_MULTI_SZ or REG_BINARY. Values written as hex(n) with an unknown type n are typed REG_0xN."},
+			{Name: "value", Type: proto.ColumnType_JSON, Transform: transform.FromField("Value"), Description: "The decoded value, i.e. a string for string types, a number for REG_DWORD and REG_QWORD, an array of strings for REG_MULTI_SZ and a hex string for other types."},
+			{Name: "raw_value", Type: proto.ColumnType_STRING, Description: "The data of the value as written in the file, with continuation lines joined."},
+			{Name: "line", Type: proto.ColumnType_INT, Description: "The line number of the entry in the file."},
+		},
+	}
+}
+
+type registryValue struct {
+	Path      string
+	Hive      string
+	Key       string
+	ValueName *string
+	Operation string
+	ValueType string
+	Value     interface{}
+	RawValue  string
+	Line      int
+}
+
+// registryValueTypes are the names of the registry value types, by the type
+// number used in hex(n) data.
+var registryValueTypes = map[uint64]string{
+	0:  "REG_NONE",
+	1:  "REG_SZ",
+	2:  "REG_EXPAND_SZ",
+	3:  "REG_BINARY",
+	4:  "REG_DWORD",
+	5:  "REG_DWORD_BIG_ENDIAN",
+	6:  "REG_LINK",
+	7:  "REG_MULTI_SZ",
+	8:  "REG_RESOURCE_LIST",
+	9:  "REG_FULL_RESOURCE_DESCRIPTOR",
+	10: "REG_RESOURCE_REQUIREMENTS_LIST",
+	11: "REG_QWORD",
+}
+
+func listRegistryValues(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// #1 - Path via qual
+	// If the path was requested through qualifier then match it exactly. Globs
+	// are not supported in this context since the output value for the column
+	// will never match the requested value.
+	//
+	// #2 - Path via glob paths in config
+	var paths []string
+	if d.EqualsQuals["path"] != nil {
+		paths = []string{d.EqualsQuals["path"].GetStringValue()}
+	} else {
+		var err error
+		paths, err = listRegFiles(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			plugin.Logger(ctx).Error("registry_value.listRegistryValues", "file_error", err, "path", path)
+			return nil, fmt.Errorf("fail to read file %s: %v", path, err)
+		}
+		rows, err := parseRegFile(content)
+		if err != nil {
+			plugin.Logger(ctx).Error("registry_value.listRegistryValues", "parse_error", err, "path", path)
+			return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
+		}
+		for _, row := range rows {
+			row.Path = path
+			d.StreamListItem(ctx, row)
+		}
+	}
+	return nil, nil
+}
+
+// decodeRegFile returns the text of a .reg file. Files exported by regedit
+// since Windows 2000 are UTF-16 with a byte order mark, while REGEDIT4 files
+// use the ANSI code page, which is assumed to be Windows-1252 unless the file
+// is valid UTF-8.
+func decodeRegFile(content []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(content, []byte{0xFF, 0xFE}), bytes.HasPrefix(content, []byte{0xFE, 0xFF}):
+		var order binary.ByteOrder = binary.LittleEndian
+		if content[0] == 0xFE {
+			order = binary.BigEndian
+		}
+		units := make([]uint16, (len(content)-2)/2)
+		for n := range units {
+			units[n] = order.Uint16(content[2+2*n:])
+		}
+		return string(utf16.Decode(units)), nil
+	case bytes.HasPrefix(content, []byte{0xEF, 0xBB, 0xBF}):
+		return string(content[3:]), nil
+	case utf8.Valid(content):
+		return string(content), nil
+	}
+	return charmap.Windows1252.NewDecoder().String(string(content))
+}
+
+// parseRegFile parses the keys and values of a .reg file. Keys written as
+// [-KEY] and values written as "name"=- are deletions. Hex data ending with a
+// backslash continues on the next line.
+func parseRegFile(content []byte) ([]registryValue, error) {
+	text, err := decodeRegFile(content)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	// The header sets how strings in hex data are encoded
+	unicode := false
+	n := 0
+	for n < len(lines) && strings.TrimSpace(lines[n]) == "" {
+		n++
+	}
+	if n == len(lines) {
+		return nil, fmt.Errorf("empty file, expecting a REGEDIT4 or Windows Registry Editor Version 5.00 header")
+	}
+	switch header := strings.TrimSpace(lines[n]); header {
+	case "Windows Registry Editor Version 5.00":
+		unicode = true
+	case "REGEDIT4":
+	default:
+		return nil, fmt.Errorf("unexpected header %q, expecting REGEDIT4 or Windows Registry Editor Version 5.00", header)
+	}
+
+	var rows []registryValue
+	key := ""
+	for n++; n < len(lines); n++ {
+		start := n
+		line := strings.TrimSpace(lines[n])
+		if line == "" || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			end := strings.LastIndex(line, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("key on line %d is not closed by ]", start+1)
+			}
+			key = line[1:end]
+			if strings.HasPrefix(key, "-") {
+				key = key[1:]
+				rows = append(rows, registryValue{Hive: registryHive(key), Key: key, Operation: "delete_key", Line: start + 1})
+			}
+			continue
+		}
+
+		for strings.HasSuffix(line, "\\") && n+1 < len(lines) {
+			n++
+			line = strings.TrimSuffix(line, "\\") + strings.TrimSpace(lines[n])
+		}
+		if key == "" {
+			return nil, fmt.Errorf("value on line %d is not in a key", start+1)
+		}
+		name, data, err := splitRegValue(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", start+1, err)
+		}
+		row := registryValue{Hive: registryHive(key), Key: key, ValueName: &name, Operation: "set", RawValue: data, Line: start + 1}
+		if data == "-" {
+			row.Operation = "delete_value"
+		} else if row.ValueType, row.Value, err = decodeRegData(data, unicode); err != nil {
+			return nil, fmt.Errorf("line %d: %v", start+1, err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// registryHive returns the root key of a key path.
+func registryHive(key string) string {
+	hive, _, _ := strings.Cut(key, "\\")
+	return hive
+}
+
+// splitRegValue splits a value line into the name of the value and its data.
+// The default value of a key is written as @ and has an empty name.
+func splitRegValue(line string) (string, string, error) {
+	if strings.HasPrefix(line, "@") {
+		rest := strings.TrimSpace(line[1:])
+		if !strings.HasPrefix(rest, "=") {
+			return "", "", fmt.Errorf("expecting = after @")
+		}
+		return "", strings.TrimSpace(rest[1:]), nil
+	}
+	if !strings.HasPrefix(line, `"`) {
+		return "", "", fmt.Errorf("unexpected %q, expecting a value name", line)
+	}
+	name, end, err := unquoteRegString(line)
+	if err != nil {
+		return "", "", err
+	}
+	rest := strings.TrimSpace(line[end:])
+	if !strings.HasPrefix(rest, "=") {
+		return "", "", fmt.Errorf("expecting = after value name %q", name)
+	}
+	return name, strings.TrimSpace(rest[1:]), nil
+}
+
+// unquoteRegString returns the content of the quoted string at the start of
+// s, where backslashes escape quotes and backslashes, and the length of the
+// quoted string.
+func unquoteRegString(s string) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+			}
+			b.WriteByte(s[i])
+		case '"':
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string %s", s)
+}
+
+// decodeRegData returns the type and decoded value of the data of a value.
+func decodeRegData(data string, unicode bool) (string, interface{}, error) {
+	switch {
+	case strings.HasPrefix(data, `"`):
+		s, _, err := unquoteRegString(data)
+		return "REG_SZ", s, err
+
+	case strings.HasPrefix(strings.ToLower(data), "dword:"):
+		v, err := strconv.ParseUint(strings.TrimSpace(data[len("dword:"):]), 16, 32)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid dword %q", data)
+		}
+		return "REG_DWORD", v, nil
+
+	case strings.HasPrefix(strings.ToLower(data), "hex"):
+		prefix, list, ok := strings.Cut(data, ":")
+		if !ok {
+			return "", nil, fmt.Errorf("invalid hex data %q", data)
+		}
+		var valueType uint64 = 3
+		if prefix = strings.ToLower(prefix); prefix != "hex" {
+			if !strings.HasPrefix(prefix, "hex(") || !strings.HasSuffix(prefix, ")") {
+				return "", nil, fmt.Errorf("invalid hex data %q", data)
+			}
+			v, err := strconv.ParseUint(prefix[4:len(prefix)-1], 16, 32)
+			if err != nil {
+				return "", nil, fmt.Errorf("invalid value type %q", prefix)
+			}
+			valueType = v
+		}
+		b, err := parseRegHex(list)
+		if err != nil {
+			return "", nil, err
+		}
+		name, ok := registryValueTypes[valueType]
+		if !ok {
+			name = fmt.Sprintf("REG_0x%X", valueType)
+		}
+		return name, decodeRegBytes(valueType, b, unicode), nil
+	}
+	return "", nil, fmt.Errorf("unexpected value data %q", data)
+}
+
+// parseRegHex parses a comma separated list of hex bytes.
+func parseRegHex(list string) ([]byte, error) {
+	var b []byte
+	for _, i := range strings.Split(list, ",") {
+		i = strings.TrimSpace(i)
+		if i == "" {
+			continue
+		}
+		v, err := strconv.ParseUint(i, 16, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex byte %q", i)
+		}
+		b = append(b, byte(v))
+	}
+	return b, nil
+}
+
+// decodeRegBytes decodes the bytes of a value of a given type. Strings are
+// UTF-16LE in Unicode files, and ANSI in REGEDIT4 files. Data which does not
+// fit its type is returned as a hex string, as is data of other types.
+func decodeRegBytes(valueType uint64, b []byte, unicode bool) interface{} {
+	switch valueType {
+	case 1, 2, 6:
+		s, _, _ := strings.Cut(decodeRegString(b, unicode), "\x00")
+		return s
+	case 7:
+		items := strings.Split(decodeRegString(b, unicode), "\x00")
+		for len(items) > 0 && items[len(items)-1] == "" {
+			items = items[:len(items)-1]
+		}
+		return items
+	case 4:
+		if len(b) == 4 {
+			return binary.LittleEndian.Uint32(b)
+		}
+	case 5:
+		if len(b) == 4 {
+			return binary.BigEndian.Uint32(b)
+		}
+	case 11:
+		if len(b) == 8 {
+			return binary.LittleEndian.Uint64(b)
+		}
+	}
+	return hex.EncodeToString(b)
+}
+
+func decodeRegString(b []byte, unicode bool) string {
+	if !unicode {
+		s, err := charmap.Windows1252.NewDecoder().Bytes(b)
+		if err != nil {
+			return string(b)
+		}
+		return string(s)
+	}
+	units := make([]uint16, len(b)/2)
+	for n := range units {
+		units[n] = binary.LittleEndian.Uint16(b[2*n:])
+	}
+	return string(utf16.Decode(units))
+}
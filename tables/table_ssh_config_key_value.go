@@ -0,0 +1,239 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+func tableSSHConfigKeyValue(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "ssh_config_key_value",
+		Description: "List the keyword value pairs of OpenSSH client and server config files, such as ~/.ssh/config and sshd_config.",
+		List: &plugin.ListConfig{
+			Hydrate: listSSHConfigKeyValues,
+			KeyColumns: plugin.KeyColumnSlice{
+				{
+					Name:    "path",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{Name: "path", Type: proto.ColumnType_STRING, Description: "Specifies the path of the config file."},
+			{Name: "source_path", Type: proto.ColumnType_STRING, Description: "The path of the file the keyword is defined in, which differs from path for keywords in included files."},
+			{Name: "included_from", Type: proto.ColumnType_STRING, Description: "The path of the file with the Include directive which included source_path."},
+			{Name: "block_type", Type: proto.ColumnType_STRING, Description: "The type of block the keyword is in, i.e. global, host or match."},
+			{Name: "block_criteria", Type: proto.ColumnType_STRING, Description: "The patterns of a Host block, or the criteria of a Match block."},
+			{Name: "block_line", Type: proto.ColumnType_INT, Description: "The line number of the Host or Match keyword of the block."},
+			{Name: "keyword", Type: proto.ColumnType_STRING, Description: "The keyword, as written in the file. Keywords are case-insensitive."},
+			{Name: "value", Type: proto.ColumnType_STRING, Description: "The value of the keyword."},
+			{Name: "values", Type: proto.ColumnType_JSON, Description: "The value of the keyword split into its arguments, with quotes removed."},
+			{Name: "line", Type: proto.ColumnType_INT, Description: "The line number of the keyword in source_path."},
+		},
+	}
+}
+
+type sshConfigKeyValue struct {
+	Path          string
+	SourcePath    string
+	IncludedFrom  string
+	BlockType     string
+	BlockCriteria string
+	BlockLine     int
+	Keyword       string
+	Value         string
+	Values        []string
+	Line          int
+}
+
+// sshConfigMaxDepth is the maximum depth of nested Include directives, as
+// enforced by OpenSSH.
+const sshConfigMaxDepth = 16
+
+func listSSHConfigKeyValues(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// #1 - Path via qual
+	// If the path was requested through qualifier then match it exactly. Globs
+	// are not supported in this context since the output value for the column
+	// will never match the requested value.
+	//
+	// #2 - Path via glob paths in config
+	var paths []string
+	if d.EqualsQuals["path"] != nil {
+		paths = []string{d.EqualsQuals["path"].GetStringValue()}
+	} else {
+		var err error
+		paths, err = listSSHConfigFiles(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range paths {
+		block := sshConfigKeyValue{Path: path, BlockType: "global"}
+		rows, err := parseSSHConfigFile(path, "", block, map[string]bool{})
+		if err != nil {
+			plugin.Logger(ctx).Error("ssh_config_key_value.listSSHConfigKeyValues", "parse_error", err, "path", path)
+			return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
+		}
+		for _, row := range rows {
+			d.StreamListItem(ctx, row)
+		}
+	}
+	return nil, nil
+}
+
+// parseSSHConfigFile returns the keywords of a file, starting in the block
+// the file was included from. Host and Match blocks in an included file end
+// with that file, as they do in OpenSSH. including holds the files on the
+// current include stack, and files which include themselves, directly or
+// through other files, are rejected.
+func parseSSHConfigFile(path string, includedFrom string, block sshConfigKeyValue, including map[string]bool) ([]sshConfigKeyValue, error) {
+	if len(including) > sshConfigMaxDepth {
+		return nil, fmt.Errorf("too many nested Include directives in %s", includedFrom)
+	}
+	id, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if including[id] {
+		return nil, fmt.Errorf("%s includes itself", path)
+	}
+	including[id] = true
+	defer delete(including, id)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []sshConfigKeyValue
+	for n, line := range strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Keywords are separated from their value by whitespace or =
+		i := strings.IndexAny(line, " \t=")
+		if i < 0 {
+			i = len(line)
+		}
+		keyword, rest := line[:i], strings.TrimLeft(line[i:], " \t")
+		if strings.HasPrefix(rest, "=") {
+			rest = strings.TrimLeft(rest[1:], " \t")
+		}
+		values, end := splitSSHConfigArgs(rest)
+		value := strings.TrimSpace(rest[:end])
+		if len(values) == 1 {
+			value = values[0]
+		}
+
+		row := block
+		row.SourcePath = path
+		row.IncludedFrom = includedFrom
+		row.Keyword = keyword
+		row.Value = value
+		row.Values = values
+		row.Line = n + 1
+
+		switch strings.ToLower(keyword) {
+		case "host", "match":
+			block.BlockType = strings.ToLower(keyword)
+			block.BlockCriteria = value
+			block.BlockLine = n + 1
+			row.BlockType, row.BlockCriteria, row.BlockLine = block.BlockType, block.BlockCriteria, block.BlockLine
+			rows = append(rows, row)
+
+		case "include":
+			rows = append(rows, row)
+			for _, pattern := range values {
+				included, err := sshConfigIncludes(path, pattern)
+				if err != nil {
+					return nil, err
+				}
+				for _, i := range included {
+					includedRows, err := parseSSHConfigFile(i, path, block, including)
+					if err != nil {
+						return nil, err
+					}
+					rows = append(rows, includedRows...)
+				}
+			}
+
+		default:
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// sshConfigIncludes returns the files matched by the pattern of an Include
+// directive, in lexical order. Relative patterns are resolved against the
+// directory of the including file, so that exported config trees can be
+// queried from anywhere. Patterns which match no files are ignored.
+func sshConfigIncludes(path string, pattern string) ([]string, error) {
+	if strings.HasPrefix(pattern, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		pattern = filepath.Join(home, pattern[2:])
+	} else if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(filepath.Dir(path), pattern)
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Include pattern %q: %v", pattern, err)
+	}
+	sort.Strings(matches)
+
+	var files []string
+	for _, i := range matches {
+		if info, err := os.Stat(i); err == nil && !info.IsDir() {
+			files = append(files, i)
+		}
+	}
+	return files, nil
+}
+
+// splitSSHConfigArgs splits a value into arguments separated by whitespace,
+// where double quotes group arguments. An unquoted argument starting with #
+// starts a comment. It returns the arguments and the length of the value
+// before any comment.
+func splitSSHConfigArgs(s string) ([]string, int) {
+	var args []string
+	var arg strings.Builder
+	inArg, quoted := false, false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"':
+			quoted = !quoted
+			inArg = true
+		case quoted:
+			arg.WriteByte(c)
+		case c == ' ' || c == '\t':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		case c == '#' && !inArg:
+			return args, i
+		default:
+			arg.WriteByte(c)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, len(s)
+}
@@ -0,0 +1,319 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableSystemdUnit(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "systemd_unit",
+		Description: "List the effective settings of systemd unit files, with drop-in files layered over the base unit.",
+		List: &plugin.ListConfig{
+			Hydrate: listSystemdUnits,
+			KeyColumns: plugin.KeyColumnSlice{
+				{
+					Name:    "path",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{Name: "unit", Type: proto.ColumnType_STRING, Description: "The name of the unit, e.g. nginx.service."},
+			{Name: "path", Type: proto.ColumnType_STRING, Description: "Specifies the path of the unit file."},
+			{Name: "section", Type: proto.ColumnType_STRING, Description: "The section of the setting, e.g. Service."},
+			{Name: "key", Type: proto.ColumnType_STRING, Description: "The key of the setting, e.g. ExecStart."},
+			{Name: "value", Type: proto.ColumnType_STRING, Description: "The value of the setting, with specifiers expanded."},
+			{Name: "raw_value", Type: proto.ColumnType_STRING, Description: "The value of the setting as written in the file."},
+			{Name: "occurrence", Type: proto.ColumnType_INT, Transform: transform.FromField("Occurrence"), Description: "The zero-based index of the value among the values of the key in the section, after list resets."},
+			{Name: "is_last", Type: proto.ColumnType_BOOL, Transform: transform.FromField("IsLast"), Description: "True for the last value of the key in the section, which is the effective value of settings which are not lists."},
+			{Name: "source_path", Type: proto.ColumnType_STRING, Description: "The path of the unit file or drop-in file the setting is defined in."},
+			{Name: "line", Type: proto.ColumnType_INT, Description: "The line number of the setting in source_path."},
+		},
+	}
+}
+
+type systemdUnitSetting struct {
+	Unit       string
+	Path       string
+	Section    string
+	Key        string
+	Value      string
+	RawValue   string
+	Occurrence int
+	IsLast     bool
+	SourcePath string
+	Line       int
+}
+
+var systemdUnitTypes = map[string]bool{
+	".service": true, ".socket": true, ".device": true, ".mount": true, ".automount": true, ".swap": true,
+	".target": true, ".path": true, ".timer": true, ".slice": true, ".scope": true,
+}
+
+func listSystemdUnits(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// #1 - Path via qual
+	// If the path was requested through qualifier then match it exactly. Globs
+	// are not supported in this context since the output value for the column
+	// will never match the requested value.
+	//
+	// #2 - Path via glob paths in config
+	var paths []string
+	if d.EqualsQuals["path"] != nil {
+		paths = []string{d.EqualsQuals["path"].GetStringValue()}
+	} else {
+		var err error
+		paths, err = listSystemdFiles(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range paths {
+		// Drop-in files and dependency symlinks are read with their unit
+		switch filepath.Ext(filepath.Dir(path)) {
+		case ".d", ".wants", ".requires", ".upholds":
+			continue
+		}
+		if !systemdUnitTypes[filepath.Ext(path)] {
+			continue
+		}
+
+		settings, err := loadSystemdUnit(path)
+		if err != nil {
+			plugin.Logger(ctx).Error("systemd_unit.listSystemdUnits", "parse_error", err, "path", path)
+			return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
+		}
+		for _, i := range settings {
+			d.StreamListItem(ctx, i)
+		}
+	}
+	return nil, nil
+}
+
+// loadSystemdUnit returns the effective settings of a unit file and its
+// drop-ins. An empty assignment resets the values assigned to the key before
+// it, as it does for list settings such as ExecStart=.
+func loadSystemdUnit(path string) ([]systemdUnitSetting, error) {
+	unit := filepath.Base(path)
+	var settings []systemdUnitSetting
+	for _, i := range append([]string{path}, systemdDropIns(path)...) {
+		fileSettings, err := parseSystemdUnitFile(i)
+		if err != nil {
+			return nil, err
+		}
+		settings = append(settings, fileSettings...)
+	}
+
+	// Keep the values assigned after the last reset of each key
+	type id struct{ section, key string }
+	reset := map[id]int{}
+	for n, i := range settings {
+		if i.RawValue == "" {
+			reset[id{i.Section, i.Key}] = n + 1
+		}
+	}
+	var result []systemdUnitSetting
+	counts := map[id]int{}
+	for n, i := range settings {
+		if n < reset[id{i.Section, i.Key}] {
+			continue
+		}
+		i.Unit = unit
+		i.Path = path
+		i.Occurrence = counts[id{i.Section, i.Key}]
+		i.Value = expandSystemdSpecifiers(i.RawValue, unit, path)
+		counts[id{i.Section, i.Key}]++
+		result = append(result, i)
+	}
+	for n := range result {
+		result[n].IsLast = result[n].Occurrence == counts[id{result[n].Section, result[n].Key}]-1
+	}
+	return result, nil
+}
+
+// systemdDropIns returns the drop-in files of a unit in the order they are
+// applied. Drop-ins are read from the type, prefix, template and unit drop-in
+// directories next to the unit file, e.g. service.d, foo-.service.d,
+// foo@.service.d and foo@bar.service.d, and applied in order of file name. A
+// drop-in in a more specific directory replaces one with the same file name
+// in a more general directory.
+func systemdDropIns(path string) []string {
+	dir, name := filepath.Dir(path), filepath.Base(path)
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	prefix, _, isInstance := strings.Cut(stem, "@")
+
+	dirs := []string{strings.TrimPrefix(ext, ".") + ".d"}
+	for i := range prefix {
+		if prefix[i] == '-' && i > 0 {
+			dirs = append(dirs, prefix[:i+1]+ext+".d")
+		}
+	}
+	if isInstance && !strings.HasSuffix(stem, "@") {
+		dirs = append(dirs, prefix+"@"+ext+".d")
+	}
+	dirs = append(dirs, name+".d")
+
+	files := map[string]string{}
+	for _, i := range dirs {
+		matches, _ := filepath.Glob(filepath.Join(dir, i, "*.conf"))
+		for _, m := range matches {
+			files[filepath.Base(m)] = m
+		}
+	}
+	var names []string
+	for i := range files {
+		names = append(names, i)
+	}
+	sort.Strings(names)
+	var result []string
+	for _, i := range names {
+		result = append(result, files[i])
+	}
+	return result
+}
+
+// parseSystemdUnitFile returns the settings of a unit or drop-in file in the
+// order they are assigned. A trailing backslash continues a line, skipping
+// comment lines, and lines which are not assignments are ignored, as they are
+// by systemd.
+func parseSystemdUnitFile(path string) ([]systemdUnitSetting, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var settings []systemdUnitSetting
+	section := ""
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+	for n := 0; n < len(lines); n++ {
+		start := n
+		line := strings.TrimSpace(lines[n])
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		for strings.HasSuffix(line, "\\") {
+			line = strings.TrimSuffix(line, "\\") + " "
+			n++
+			for n < len(lines) {
+				if t := strings.TrimSpace(lines[n]); !strings.HasPrefix(t, "#") && !strings.HasPrefix(t, ";") {
+					break
+				}
+				n++
+			}
+			if n >= len(lines) {
+				break
+			}
+			line += strings.TrimSpace(lines[n])
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = line[1 : len(line)-1]
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || section == "" {
+			continue
+		}
+		settings = append(settings, systemdUnitSetting{
+			Section:    section,
+			Key:        strings.TrimSpace(key),
+			RawValue:   strings.TrimSpace(value),
+			SourcePath: path,
+			Line:       start + 1,
+		})
+	}
+	return settings, nil
+}
+
+// expandSystemdSpecifiers expands the specifiers of a value which only depend
+// on the unit, such as %n and %i, and the fixed paths of the system manager,
+// such as %t. Specifiers which depend on the host or user, such as %H and %h,
+// are kept as they are.
+func expandSystemdSpecifiers(value string, unit string, path string) string {
+	if !strings.Contains(value, "%") {
+		return value
+	}
+	stem := strings.TrimSuffix(unit, filepath.Ext(unit))
+	prefix, instance, _ := strings.Cut(stem, "@")
+	final := prefix
+	if i := strings.LastIndex(prefix, "-"); i >= 0 {
+		final = prefix[i+1:]
+	}
+	filename := "/" + systemdUnescape(prefix)
+	if instance != "" {
+		filename = "/" + systemdUnescape(instance)
+	}
+	specifiers := map[byte]string{
+		'n': unit,
+		'N': stem,
+		'p': prefix,
+		'P': systemdUnescape(prefix),
+		'i': instance,
+		'I': systemdUnescape(instance),
+		'j': final,
+		'J': systemdUnescape(final),
+		'f': strings.ReplaceAll(filename, "//", "/"),
+		'y': path,
+		'Y': filepath.Dir(path),
+		'd': "/run/credentials/" + unit,
+		'C': "/var/cache",
+		'E': "/etc",
+		'L': "/var/log",
+		'S': "/var/lib",
+		't': "/run",
+		'T': "/tmp",
+		'V': "/var/tmp",
+		's': "/bin/sh",
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '%' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		if value[i] == '%' {
+			b.WriteByte('%')
+		} else if s, ok := specifiers[value[i]]; ok {
+			b.WriteString(s)
+		} else {
+			b.WriteByte('%')
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
+
+// systemdUnescape undoes the escaping of systemd-escape, where - stands for /
+// and \xNN for any other escaped byte.
+func systemdUnescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '-':
+			b.WriteByte('/')
+		case s[i] == '\\' && i+3 < len(s) && s[i+1] == 'x':
+			if c, err := strconv.ParseUint(s[i+2:i+4], 16, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
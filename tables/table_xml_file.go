@@ -6,7 +6,6 @@ import (
 	"io"
 	"os"
 
-	"github.com/clbanning/mxj/v2"
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 )
@@ -37,6 +36,12 @@ type parseXMLContent struct {
 }
 
 func listXMLFileWithPath(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// #1 - Path via qual
+	// If the path was requested through qualifier then match it exactly. Globs
+	// are not supported in this context since the output value for the column
+	// will never match the requested value.
+	//
+	// #2 - Path via glob paths in config
 	var paths []string
 	if d.EqualsQuals["path"] != nil {
 		paths = []string{d.EqualsQuals["path"].GetStringValue()}
@@ -64,13 +69,13 @@ func listXMLFileWithPath(ctx context.Context, d *plugin.QueryData, h *plugin.Hyd
 			return nil, fmt.Errorf("failed to read file content %s: %v", path, err)
 		}
 
-		mv, err := mxj.NewMapXml(byteValue)
+		content, err := xmlToMap(byteValue, GetConfig(d.Connection).XMLOptions)
 		if err != nil {
 			plugin.Logger(ctx).Error("xml_file.listXMLFileWithPath", "parse_error", err, "path", path)
 			return nil, fmt.Errorf("failed to parse XML content %s: %v", path, err)
 		}
 
-		d.StreamListItem(ctx, parseXMLContent{path, mv})
+		d.StreamListItem(ctx, parseXMLContent{path, content})
 	}
 	return nil, nil
 }
@@ -0,0 +1,250 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/antchfx/xpath"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableXMLXPath(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "xml_xpath",
+		Description: "Evaluate an XPath expression against XML files.",
+		List: &plugin.ListConfig{
+			Hydrate: listXMLXPath,
+			KeyColumns: plugin.KeyColumnSlice{
+				{
+					Name:    "xpath",
+					Require: plugin.Required,
+				},
+				{
+					Name:    "path",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{Name: "path", Type: proto.ColumnType_STRING, Description: "Specifies the path of the XML file."},
+			{Name: "xpath", Type: proto.ColumnType_STRING, Transform: transform.FromQual("xpath"), Description: "The XPath expression evaluated against the file."},
+			{Name: "node_name", Type: proto.ColumnType_STRING, Description: "The name of the matched node, with its namespace prefix, e.g. artifactId, @version or #text."},
+			{Name: "node_type", Type: proto.ColumnType_STRING, Description: "The type of the matched node, i.e. element, attribute, text, cdata, comment, processing_instruction or document. Expressions which evaluate to a single value, such as count(//dependency), return a row of type number, string or boolean."},
+			{Name: "text", Type: proto.ColumnType_STRING, Transform: transform.FromField("Text"), Description: "The text content of the node, i.e. the concatenated text of an element and its descendants, or the value of an attribute or expression."},
+			{Name: "attributes", Type: proto.ColumnType_JSON, Description: "The attributes of an element, as an object of attribute names to values."},
+			{Name: "outer_xml", Type: proto.ColumnType_STRING, Description: "The XML of the node, including the node itself."},
+			{Name: "line", Type: proto.ColumnType_INT, Description: "The line of the node in the file. Attributes have the line of their element."},
+			{Name: "xpath_of_node", Type: proto.ColumnType_STRING, Transform: transform.FromField("XPathOfNode"), Description: "The absolute location path of the node, e.g. /project/dependencies/dependency[2]/artifactId."},
+		},
+	}
+}
+
+type xmlXPathNode struct {
+	Path        string
+	NodeName    string
+	NodeType    string
+	Text        string
+	Attributes  map[string]string
+	OuterXML    string
+	Line        int
+	XPathOfNode string
+}
+
+func listXMLXPath(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	expression := d.EqualsQuals["xpath"].GetStringValue()
+	expr, err := xpath.Compile(expression)
+	if err != nil {
+		plugin.Logger(ctx).Error("xml_xpath.listXMLXPath", "xpath_error", err, "xpath", expression)
+		return nil, fmt.Errorf("invalid xpath %q: %v", expression, err)
+	}
+
+	// #1 - Path via qual
+	// If the path was requested through qualifier then match it exactly. Globs
+	// are not supported in this context since the output value for the column
+	// will never match the requested value.
+	//
+	// #2 - Path via glob paths in config
+	var paths []string
+	if d.EqualsQuals["path"] != nil {
+		paths = []string{d.EqualsQuals["path"].GetStringValue()}
+	} else {
+		paths, err = listXMLFiles(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			plugin.Logger(ctx).Error("xml_xpath.listXMLXPath", "read_error", err, "path", path)
+			return nil, fmt.Errorf("fail to read file %s: %v", path, err)
+		}
+		doc, err := parseXMLDocument(content, GetConfig(d.Connection).XMLOptions)
+		if err != nil {
+			plugin.Logger(ctx).Error("xml_xpath.listXMLXPath", "parse_error", err, "path", path)
+			return nil, fmt.Errorf("failed to parse XML content %s: %v", path, err)
+		}
+
+		positions := xpathPositions{}
+		switch result := expr.Evaluate(xmlquery.CreateXPathNavigator(doc)).(type) {
+		case *xpath.NodeIterator:
+			for result.MoveNext() {
+				node := xmlXPathNodeOf(result.Current().(*xmlquery.NodeNavigator), positions)
+				node.Path = path
+				d.StreamListItem(ctx, node)
+			}
+		case float64:
+			d.StreamListItem(ctx, xmlXPathNode{Path: path, NodeType: "number", Text: strconv.FormatFloat(result, 'f', -1, 64)})
+		case string:
+			d.StreamListItem(ctx, xmlXPathNode{Path: path, NodeType: "string", Text: result})
+		case bool:
+			d.StreamListItem(ctx, xmlXPathNode{Path: path, NodeType: "boolean", Text: strconv.FormatBool(result)})
+		}
+	}
+	return nil, nil
+}
+
+// xmlXPathNodeOf returns the row of the current node of an XPath result.
+func xmlXPathNodeOf(nav *xmlquery.NodeNavigator, positions xpathPositions) xmlXPathNode {
+	n := nav.Current()
+	if nav.NodeType() == xpath.AttributeNode {
+		name := xmlQualifiedName(nav.Prefix(), nav.LocalName())
+		return xmlXPathNode{
+			NodeName:    "@" + name,
+			NodeType:    "attribute",
+			Text:        nav.Value(),
+			OuterXML:    name + `="` + xmlEscape(nav.Value()) + `"`,
+			Line:        n.LineNumber,
+			XPathOfNode: positions.xpathOfNode(n) + "/@" + name,
+		}
+	}
+
+	node := xmlXPathNode{
+		NodeName:    xmlNodeName(n),
+		NodeType:    xmlNodeTypes[n.Type],
+		Text:        n.InnerText(),
+		OuterXML:    n.OutputXMLWithOptions(xmlquery.WithOutputSelf(), xmlquery.WithPreserveSpace()),
+		Line:        n.LineNumber,
+		XPathOfNode: positions.xpathOfNode(n),
+	}
+	if n.Type == xmlquery.ElementNode {
+		node.Attributes = map[string]string{}
+		for _, a := range n.Attr {
+			node.Attributes[xmlQualifiedName(a.Name.Space, a.Name.Local)] = a.Value
+		}
+	}
+	if n.Type == xmlquery.CommentNode {
+		node.Text = n.Data
+	}
+	if n.Type == xmlquery.DocumentNode {
+		node.OuterXML = n.OutputXMLWithOptions(xmlquery.WithPreserveSpace())
+	}
+	return node
+}
+
+var xmlNodeTypes = map[xmlquery.NodeType]string{
+	xmlquery.DocumentNode:          "document",
+	xmlquery.DeclarationNode:       "processing_instruction",
+	xmlquery.ElementNode:           "element",
+	xmlquery.TextNode:              "text",
+	xmlquery.CharDataNode:          "cdata",
+	xmlquery.CommentNode:           "comment",
+	xmlquery.AttributeNode:         "attribute",
+	xmlquery.NotationNode:          "notation",
+	xmlquery.ProcessingInstruction: "processing_instruction",
+}
+
+// xmlNodeName returns the name of a node as in the DOM, e.g. #text for text
+// nodes, and the target of processing instructions.
+func xmlNodeName(n *xmlquery.Node) string {
+	switch n.Type {
+	case xmlquery.DocumentNode:
+		return "#document"
+	case xmlquery.TextNode:
+		return "#text"
+	case xmlquery.CharDataNode:
+		return "#cdata-section"
+	case xmlquery.CommentNode:
+		return "#comment"
+	case xmlquery.ProcessingInstruction:
+		if n.ProcInst != nil {
+			return n.ProcInst.Target
+		}
+	}
+	return xmlQualifiedName(n.Prefix, n.Data)
+}
+
+func xmlQualifiedName(prefix, local string) string {
+	if prefix == "" {
+		return local
+	}
+	return prefix + ":" + local
+}
+
+// xpathPositions caches the positions of nodes among their siblings of the
+// same name, computed once for all the children of a parent, so paths of
+// many siblings are built in linear time.
+type xpathPositions map[*xmlquery.Node]xpathPosition
+
+type xpathPosition struct {
+	// position is the one-based position of the node among its siblings with
+	// the same step, and count the number of such siblings
+	position, count int
+}
+
+// xpathOfNode returns the absolute location path of a node. Positions are
+// only included for nodes with siblings of the same name, so the path of the
+// node can be evaluated to select it again.
+func (c xpathPositions) xpathOfNode(n *xmlquery.Node) string {
+	var steps []string
+	for ; n != nil && n.Type != xmlquery.DocumentNode; n = n.Parent {
+		step := xpathStep(n)
+		p := c.of(n)
+		if p.count > 1 {
+			step += "[" + strconv.Itoa(p.position) + "]"
+		}
+		steps = append([]string{step}, steps...)
+	}
+	return "/" + strings.Join(steps, "/")
+}
+
+func (c xpathPositions) of(n *xmlquery.Node) xpathPosition {
+	if p, ok := c[n]; ok {
+		return p
+	}
+	counts := map[string]int{}
+	var children []*xmlquery.Node
+	for s := n.Parent.FirstChild; s != nil; s = s.NextSibling {
+		step := xpathStep(s)
+		counts[step]++
+		c[s] = xpathPosition{position: counts[step]}
+		children = append(children, s)
+	}
+	for _, s := range children {
+		p := c[s]
+		p.count = counts[xpathStep(s)]
+		c[s] = p
+	}
+	return c[n]
+}
+
+// xpathStep returns the location step which selects a node among its
+// siblings, before any position predicate.
+func xpathStep(n *xmlquery.Node) string {
+	switch n.Type {
+	case xmlquery.TextNode, xmlquery.CharDataNode:
+		return "text()"
+	case xmlquery.CommentNode:
+		return "comment()"
+	case xmlquery.DeclarationNode, xmlquery.ProcessingInstruction:
+		return fmt.Sprintf("processing-instruction('%s')", xmlNodeName(n))
+	}
+	return xmlQualifiedName(n.Prefix, n.Data)
+}
@@ -2,11 +2,14 @@ package config
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
@@ -25,16 +28,28 @@ func tableYMLKeyValue(ctx context.Context) *plugin.Table {
 					Name:    "path",
 					Require: plugin.Optional,
 				},
+				{
+					Name:    "include_containers",
+					Require: plugin.Optional,
+				},
 			},
 		},
 		Columns: []*plugin.Column{
 			{Name: "path", Type: proto.ColumnType_STRING, Description: "Specifies the path of the YML file."},
 			{Name: "key_path", Type: proto.ColumnType_LTREE, Transform: transform.FromField("Key").Transform(keysToSnakeCase), Description: "Specifies full path of a key in YML file."},
 			{Name: "value", Type: proto.ColumnType_STRING, Description: "Specifies the value of the corresponding key."},
+			{Name: "value_number", Type: proto.ColumnType_DOUBLE, Transform: transform.FromField("ValueNumber"), Description: "The value of the corresponding key, if it is a number."},
+			{Name: "value_bool", Type: proto.ColumnType_BOOL, Transform: transform.FromField("ValueBool"), Description: "The value of the corresponding key, if it is a boolean."},
+			{Name: "value_timestamp", Type: proto.ColumnType_TIMESTAMP, Transform: transform.FromField("ValueTimestamp"), Description: "The value of the corresponding key, if it is a timestamp or date."},
+			{Name: "value_json", Type: proto.ColumnType_JSON, Transform: transform.FromField("ValueJSON"), Description: "The value of the corresponding key as a typed JSON value."},
 			{Name: "keys", Type: proto.ColumnType_JSON, Transform: transform.FromField("Key"), Description: "The array representation of path of a key."},
+			{Name: "node_kind", Type: proto.ColumnType_STRING, Description: "The kind of the node, i.e. scalar, mapping or sequence."},
+			{Name: "child_count", Type: proto.ColumnType_INT, Transform: transform.FromField("ChildCount"), Description: "The number of entries in a mapping or sequence."},
+			{Name: "include_containers", Type: proto.ColumnType_BOOL, Transform: transform.FromQual("include_containers"), Description: "If true, rows are also returned for non-empty mappings and sequences, with the subtree in value_json. Defaults to false."},
 			{Name: "tag", Type: proto.ColumnType_STRING, Description: "Specifies the data type of the value."},
 			{Name: "start_line", Type: proto.ColumnType_INT, Description: "Specifies the line number where the value is located."},
 			{Name: "start_column", Type: proto.ColumnType_INT, Description: "Specifies the starting column of the value."},
+			{Name: "end_line", Type: proto.ColumnType_INT, Description: "Specifies the last line of the value, including all descendants of a mapping or sequence."},
 			{Name: "pre_comments", Type: proto.ColumnType_JSON, Description: "Specifies the comments added above a key."},
 			{Name: "head_comment", Type: proto.ColumnType_STRING, Description: "Specifies the comment in the lines preceding the node and not separated by an empty line."},
 			{Name: "line_comment", Type: proto.ColumnType_STRING, Description: "Specifies the comment at the end of the line where the node is in."},
@@ -79,7 +94,8 @@ func listYMLKeyValue(ctx context.Context, d *plugin.QueryData, h *plugin.Hydrate
 		}
 
 		var rows Rows
-		treeToList(&root, []string{}, &rows, nil, nil, nil)
+		includeContainers := d.EqualsQuals["include_containers"] != nil && d.EqualsQuals["include_containers"].GetBoolValue()
+		treeToList(&root, []string{}, &rows, nil, nil, nil, includeContainers)
 		for _, r := range rows {
 			r.Path = path
 			d.StreamListItem(ctx, r)
@@ -90,19 +106,71 @@ func listYMLKeyValue(ctx context.Context, d *plugin.QueryData, h *plugin.Hydrate
 
 type Rows []Row
 type Row struct {
-	Path        string
-	Key         []string
-	Value       interface{}
-	Tag         *string
-	PreComments []string
-	HeadComment string
-	LineComment string
-	FootComment string
-	StartLine   int
-	StartColumn int
+	Path           string
+	Key            []string
+	Value          interface{}
+	ValueNumber    *float64
+	ValueBool      *bool
+	ValueTimestamp *time.Time
+	ValueJSON      interface{}
+	Tag            *string
+	PreComments    []string
+	HeadComment    string
+	LineComment    string
+	FootComment    string
+	NodeKind       string
+	ChildCount     int
+	StartLine      int
+	StartColumn    int
+	EndLine        int
+
+	// node is the YAML node the row was flattened from
+	node *yaml.Node
+}
+
+// treeToList flattens a YAML node into rows, one per scalar value or empty
+// collection. If includeContainers is set, a row is also added for each
+// mapping and sequence, holding its subtree as JSON. The JSON value of each
+// subtree is built once from the values of its children, as by nodeToJSON.
+func treeToList(tree *yaml.Node, prefix []string, rows *Rows, preComments []string, headComments []string, footComments []string, includeContainers bool) {
+	f := &yamlFlattener{rows: rows}
+	if includeContainers {
+		f.json = &yamlJSONConverter{visiting: map[*yaml.Node]bool{}}
+	}
+	f.flatten(tree, prefix, preComments, headComments, footComments)
+
+	// As with nodeToJSON, subtrees expanding to too many nodes through
+	// aliases are null
+	if f.json != nil && f.json.nodes > yamlMaxResolvedNodes {
+		for _, i := range f.containers {
+			(*rows)[i].ValueJSON = nil
+		}
+	}
+}
+
+type yamlFlattener struct {
+	rows *Rows
+	// json converts the subtrees of container rows, or is nil if containers
+	// are not included
+	json *yamlJSONConverter
+	// containers holds the indexes of the container rows added
+	containers []int
 }
 
-func treeToList(tree *yaml.Node, prefix []string, rows *Rows, preComments []string, headComments []string, footComments []string) {
+// flatten adds the rows of a node, returning its JSON value if containers
+// are included.
+func (f *yamlFlattener) flatten(tree *yaml.Node, prefix []string, preComments []string, headComments []string, footComments []string) interface{} {
+	includeContainers := f.json != nil
+	if includeContainers && tree.Kind != yaml.ScalarNode && tree.Kind != yaml.AliasNode {
+		f.json.nodes++
+		if tree.Anchor != "" {
+			f.json.visiting[tree] = true
+			defer delete(f.json.visiting, tree)
+		}
+	}
+	values := make([]interface{}, len(tree.Content))
+	container := -1
+
 	switch tree.Kind {
 	case yaml.DocumentNode:
 		for i, v := range tree.Content {
@@ -122,22 +190,32 @@ func treeToList(tree *yaml.Node, prefix []string, rows *Rows, preComments []stri
 					localComments = append(localComments, tree.LineComment)
 				}
 			}
-			treeToList(v, prefix, rows, localComments, headComments, footComments)
+			values[i] = f.flatten(v, prefix, localComments, headComments, footComments)
 		}
 	case yaml.SequenceNode:
-		if len(tree.Content) == 0 {
+		if len(tree.Content) == 0 || includeContainers {
 			row := Row{
+				node:        tree,
 				Key:         prefix,
 				Value:       []string{},
+				ValueJSON:   []interface{}{},
 				Tag:         &tree.Tag,
+				NodeKind:    "sequence",
+				ChildCount:  len(tree.Content),
 				StartLine:   tree.Line,
 				StartColumn: tree.Column,
+				EndLine:     nodeEndLine(tree),
 				PreComments: preComments,
 				HeadComment: strings.Join(headComments, ","),
 				LineComment: tree.LineComment,
 				FootComment: strings.Join(footComments, ","),
 			}
-			*rows = append(*rows, row)
+			if len(tree.Content) > 0 {
+				row.Value = nil
+				container = len(*f.rows)
+				f.containers = append(f.containers, container)
+			}
+			*f.rows = append(*f.rows, row)
 		}
 
 		for i, v := range tree.Content {
@@ -157,7 +235,7 @@ func treeToList(tree *yaml.Node, prefix []string, rows *Rows, preComments []stri
 			newKey := make([]string, len(prefix))
 			copy(newKey, prefix)
 			newKey = append(newKey, strconv.Itoa(i))
-			treeToList(v, newKey, rows, localComments, headComments, footComments)
+			values[i] = f.flatten(v, newKey, localComments, headComments, footComments)
 		}
 	case yaml.MappingNode:
 		localComments := []string{}
@@ -174,19 +252,29 @@ func treeToList(tree *yaml.Node, prefix []string, rows *Rows, preComments []stri
 		if tree.LineComment != "" {
 			localComments = append(localComments, tree.LineComment)
 		}
-		if len(tree.Content) == 0 {
+		if len(tree.Content) == 0 || includeContainers {
 			row := Row{
+				node:        tree,
 				Key:         prefix,
 				Value:       map[string]interface{}{},
+				ValueJSON:   map[string]interface{}{},
 				Tag:         &tree.Tag,
+				NodeKind:    "mapping",
+				ChildCount:  len(tree.Content) / 2,
 				StartLine:   tree.Line,
 				StartColumn: tree.Column,
+				EndLine:     nodeEndLine(tree),
 				PreComments: preComments,
 				HeadComment: strings.Join(headComments, ","),
 				LineComment: tree.LineComment,
 				FootComment: strings.Join(footComments, ","),
 			}
-			*rows = append(*rows, row)
+			if len(tree.Content) > 0 {
+				row.Value = nil
+				container = len(*f.rows)
+				f.containers = append(f.containers, container)
+			}
+			*f.rows = append(*f.rows, row)
 		}
 		i := 0
 		for i < len(tree.Content)-1 {
@@ -206,18 +294,21 @@ func treeToList(tree *yaml.Node, prefix []string, rows *Rows, preComments []stri
 			newKey := make([]string, len(prefix))
 			copy(newKey, prefix)
 			newKey = append(newKey, key.Value)
-			treeToList(val, newKey, rows, localComments, headComments, footComments)
+			values[i-1] = f.flatten(val, newKey, localComments, headComments, footComments)
 			localComments = make([]string, 0)
 			headComments = make([]string, 0)
 			footComments = make([]string, 0)
 		}
 	case yaml.ScalarNode:
 		row := Row{
+			node:        tree,
 			Key:         prefix,
 			Value:       tree.Value,
 			Tag:         &tree.Tag,
+			NodeKind:    "scalar",
 			StartLine:   tree.Line,
 			StartColumn: tree.Column,
+			EndLine:     nodeEndLine(tree),
 			PreComments: preComments,
 			HeadComment: strings.Join(headComments, ","),
 			LineComment: tree.LineComment,
@@ -226,8 +317,195 @@ func treeToList(tree *yaml.Node, prefix []string, rows *Rows, preComments []stri
 		if tree.Tag == "!!null" {
 			row.Value = nil
 		}
-		*rows = append(*rows, row)
+		setTypedValue(&row, tree)
+		*f.rows = append(*f.rows, row)
+		if includeContainers {
+			f.json.nodes++
+		}
+		return row.ValueJSON
+	case yaml.AliasNode:
+		if includeContainers {
+			return f.json.convert(tree)
+		}
+		return nil
+	}
+
+	if !includeContainers {
+		return nil
+	}
+	var value interface{}
+	switch tree.Kind {
+	case yaml.DocumentNode:
+		if len(values) > 0 {
+			value = values[0]
+		}
+	case yaml.SequenceNode:
+		value = values
+	case yaml.MappingNode:
+		value = f.json.mapping(tree, values)
+	}
+	if container >= 0 {
+		(*f.rows)[container].ValueJSON = value
+	}
+	return value
+}
+
+// nodeEndLine returns the last line of a node, i.e. the last line of its
+// deepest descendant for collections, or of the content of block scalars.
+func nodeEndLine(node *yaml.Node) int {
+	if len(node.Content) > 0 {
+		return nodeEndLine(node.Content[len(node.Content)-1])
+	}
+	if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		// Block scalar content starts on the line after the indicator
+		return node.Line + strings.Count(strings.TrimRight(node.Value, "\n"), "\n") + 1
+	}
+	return node.Line
+}
+
+// nodeToJSON converts a node and its descendants into a value which can be
+// represented as JSON, or nil if it expands to more than
+// yamlMaxResolvedNodes nodes through aliases. Aliases referring to their own
+// anchor are converted to null. Mapping keys which are not
+// strings, e.g. 1: a or true: b, are converted to their text, and complex
+// keys to their JSON text. Infinity and NaN are kept as strings.
+func nodeToJSON(node *yaml.Node) interface{} {
+	c := &yamlJSONConverter{visiting: map[*yaml.Node]bool{}}
+	value := c.convert(node)
+	if c.nodes > yamlMaxResolvedNodes {
+		return nil
+	}
+	return value
+}
+
+type yamlJSONConverter struct {
+	nodes    int
+	visiting map[*yaml.Node]bool
+}
+
+func (c *yamlJSONConverter) convert(node *yaml.Node) interface{} {
+	c.nodes++
+	if c.nodes > yamlMaxResolvedNodes || c.visiting[node] {
+		return nil
+	}
+	if node.Anchor != "" {
+		c.visiting[node] = true
+		defer delete(c.visiting, node)
+	}
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return c.convert(node.Content[0])
+	case yaml.AliasNode:
+		if node.Alias == nil {
+			return nil
+		}
+		return c.convert(node.Alias)
+	case yaml.SequenceNode, yaml.MappingNode:
+		values := make([]interface{}, len(node.Content))
+		for i, child := range node.Content {
+			// Mapping keys are converted by key
+			if node.Kind == yaml.SequenceNode || i%2 == 1 {
+				values[i] = c.convert(child)
+			}
+		}
+		if node.Kind == yaml.SequenceNode {
+			return values
+		}
+		return c.mapping(node, values)
+	}
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return node.Value
+	}
+	if f, ok := value.(float64); ok && (math.IsInf(f, 0) || math.IsNaN(f)) {
+		return node.Value
+	}
+	return value
+}
+
+// mapping returns the JSON value of a mapping from the values of its content,
+// in which only the values at odd indexes, i.e. of the mapping values, are
+// used. Merge keys (<<) are expanded.
+func (c *yamlJSONConverter) mapping(node *yaml.Node, values []interface{}) interface{} {
+	result := map[string]interface{}{}
+	var merged []interface{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], values[i+1]
+		if key.ShortTag() == "!!merge" {
+			if m, ok := value.([]interface{}); ok {
+				merged = append(merged, m...)
+			} else {
+				merged = append(merged, value)
+			}
+			continue
+		}
+		result[c.key(key)] = value
+	}
+	// Explicit keys take precedence over merged ones, and earlier merged
+	// mappings over later ones
+	for _, m := range merged {
+		m, _ := m.(map[string]interface{})
+		for k, v := range m {
+			if _, ok := result[k]; !ok {
+				result[k] = v
+			}
+		}
+	}
+	return result
+}
+
+// key returns the string form of a mapping key.
+func (c *yamlJSONConverter) key(node *yaml.Node) string {
+	if node.Kind == yaml.AliasNode && node.Alias != nil && node.Alias.Kind == yaml.ScalarNode {
+		return node.Alias.Value
+	}
+	if node.Kind == yaml.ScalarNode {
+		return node.Value
+	}
+	b, err := json.Marshal(c.convert(node))
+	if err != nil {
+		return node.Value
+	}
+	return string(b)
+}
+
+// setTypedValue populates the typed value columns of a row from a scalar node,
+// based on the type resolved by the YAML decoder.
+func setTypedValue(row *Row, node *yaml.Node) {
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		row.ValueJSON = node.Value
+		return
+	}
+
+	switch v := value.(type) {
+	case int:
+		f := float64(v)
+		row.ValueNumber = &f
+	case int64:
+		f := float64(v)
+		row.ValueNumber = &f
+	case uint64:
+		f := float64(v)
+		row.ValueNumber = &f
+	case float64:
+		// Infinity and NaN have no JSON representation
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			value = node.Value
+			break
+		}
+		row.ValueNumber = &v
+	case bool:
+		row.ValueBool = &v
+	case time.Time:
+		row.ValueTimestamp = &v
+	case string:
+		row.ValueTimestamp = parseTimestamp(v)
 	}
+	row.ValueJSON = value
 }
 
 func keysToSnakeCase(_ context.Context, d *transform.TransformData) (interface{}, error) {
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"gopkg.in/yaml.v3"
+)
+
+// parseTOMLNode parses TOML content into a YAML document node, with the line
+// numbers of keys and values, so TOML files can be walked in the same way as
+// JSON and YAML files. Values are kept as scalars with their TOML text and a
+// matching YAML tag.
+func parseTOMLNode(content []byte) (*yaml.Node, error) {
+	// The parser does not detect semantic errors, such as duplicate keys, so
+	// validate the document first
+	var data interface{}
+	if err := toml.Unmarshal(content, &data); err != nil {
+		return nil, err
+	}
+
+	p := &unstable.Parser{}
+	p.Reset(content)
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: 1, Column: 1}
+	current := root
+	for p.NextExpression() {
+		e := p.Expression()
+		switch e.Kind {
+		case unstable.Table:
+			current = tomlTable(p, root, tomlKeys(e.Key()))
+		case unstable.ArrayTable:
+			keys := tomlKeys(e.Key())
+			parent := tomlTable(p, root, keys[:len(keys)-1])
+			key := keys[len(keys)-1]
+			seq := mappingValue(parent, string(key.Data))
+			if seq == nil {
+				seq = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: tomlLine(p, key, 0)}
+				parent.Content = append(parent.Content, tomlKeyNode(p, key), seq)
+			}
+			current = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: tomlLine(p, key, 0)}
+			seq.Content = append(seq.Content, current)
+		case unstable.KeyValue:
+			tomlSetKeyValue(p, current, e)
+		}
+	}
+	if err := p.Error(); err != nil {
+		return nil, err
+	}
+	return &yaml.Node{Kind: yaml.DocumentNode, Line: 1, Column: 1, Content: []*yaml.Node{root}}, nil
+}
+
+func tomlKeys(it unstable.Iterator) []*unstable.Node {
+	var keys []*unstable.Node
+	for it.Next() {
+		keys = append(keys, it.Node())
+	}
+	return keys
+}
+
+// tomlLine returns the line of a node, or the given line if the parser does
+// not record the position of the node.
+func tomlLine(p *unstable.Parser, n *unstable.Node, line int) int {
+	if n.Raw.Length == 0 {
+		return line
+	}
+	return p.Shape(n.Raw).Start.Line
+}
+
+func tomlKeyNode(p *unstable.Parser, key *unstable.Node) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: string(key.Data), Line: tomlLine(p, key, 0)}
+}
+
+// tomlTable returns the mapping at a dotted key, creating missing tables. A key
+// holding an array of tables refers to its last table, as in TOML.
+func tomlTable(p *unstable.Parser, node *yaml.Node, keys []*unstable.Node) *yaml.Node {
+	for _, key := range keys {
+		next := mappingValue(node, string(key.Data))
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: tomlLine(p, key, 0)}
+			node.Content = append(node.Content, tomlKeyNode(p, key), next)
+		}
+		if next.Kind == yaml.SequenceNode && len(next.Content) > 0 {
+			next = next.Content[len(next.Content)-1]
+		}
+		node = next
+	}
+	return node
+}
+
+// tomlSetKeyValue adds a key value expression to a mapping. Dotted keys create
+// nested tables.
+func tomlSetKeyValue(p *unstable.Parser, node *yaml.Node, e *unstable.Node) {
+	keys := tomlKeys(e.Key())
+	parent := tomlTable(p, node, keys[:len(keys)-1])
+	key := tomlKeyNode(p, keys[len(keys)-1])
+	parent.Content = append(parent.Content, key, tomlValue(p, e.Value(), key.Line))
+}
+
+var tomlTags = map[unstable.Kind]string{
+	unstable.String:        "!!str",
+	unstable.Bool:          "!!bool",
+	unstable.Float:         "!!float",
+	unstable.Integer:       "!!int",
+	unstable.LocalDate:     "!!timestamp",
+	unstable.LocalDateTime: "!!timestamp",
+	unstable.DateTime:      "!!timestamp",
+	unstable.LocalTime:     "!!str",
+}
+
+func tomlValue(p *unstable.Parser, v *unstable.Node, line int) *yaml.Node {
+	line = tomlLine(p, v, line)
+	switch v.Kind {
+	case unstable.Array:
+		seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: line}
+		it := v.Children()
+		for it.Next() {
+			seq.Content = append(seq.Content, tomlValue(p, it.Node(), line))
+		}
+		return seq
+	case unstable.InlineTable:
+		m := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: line, Style: yaml.FlowStyle}
+		it := v.Children()
+		for it.Next() {
+			tomlSetKeyValue(p, m, it.Node())
+		}
+		return m
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tomlTags[v.Kind], Value: string(v.Data), Line: line}
+}
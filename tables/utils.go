@@ -3,7 +3,18 @@ package config
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/ini.v1"
+	"gopkg.in/yaml.v3"
 
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 )
@@ -68,6 +79,284 @@ func listINIFiles(ctx context.Context, d *plugin.QueryData) ([]string, error) {
 	return listFilesByType(ctx, d, cfg.INIPaths, "ini_paths must be configured to query INI files")
 }
 
+// loadINIContent parses the content of an INI file using the ini_options
+// configured for the connection, so all INI tables agree on the dialect.
+// Along with the parsed file it returns the line numbers of each section and
+// key.
+func loadINIContent(d *plugin.QueryData, content []byte) (*ini.File, iniLineIndex, error) {
+	opts := GetConfig(d.Connection).INIOptions.loadOptions()
+	cfg, err := ini.LoadSources(opts, content)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, indexINILines(content, opts), nil
+}
+
+// loadINIShadows parses the content of an INI file with shadows allowed, so
+// all INI tables return every occurrence of a repeated key whether or not
+// allow_shadows is enabled. If it is, cfg already keeps them and is returned
+// as-is. References are still resolved with cfg.
+func loadINIShadows(d *plugin.QueryData, content []byte, cfg *ini.File) (*ini.File, error) {
+	opts := GetConfig(d.Connection).INIOptions.loadOptions()
+	if opts.AllowShadows {
+		return cfg, nil
+	}
+	opts.AllowShadows, opts.AllowDuplicateShadowValues = true, true
+	return ini.LoadSources(opts, content)
+}
+
+// iniShadowSection returns the section of shadows matching the given
+// occurrence of a section, since sections with the same name are only kept
+// apart if allow_non_unique_sections is enabled.
+func iniShadowSection(shadows *ini.File, section *ini.Section, occurrence int) *ini.Section {
+	if sections, err := shadows.SectionsByName(section.Name()); err == nil && occurrence < len(sections) {
+		return sections[occurrence]
+	}
+	return section
+}
+
+// iniKeyValues returns the raw value of every occurrence of a key, looked up
+// in the matching section of the file loaded with shadows.
+func iniKeyValues(shadowSection *ini.Section, key *ini.Key) []string {
+	var values []string
+	if shadowKey, err := shadowSection.GetKey(key.Name()); err == nil {
+		values = shadowKey.ValueWithShadows()
+	}
+	if len(values) == 0 {
+		values = []string{key.Value()}
+	}
+	return values
+}
+
+// iniLineIndex holds the line numbers of the sections and keys in an INI
+// file, keyed by section name, since go-ini does not keep track of them.
+// Sections with the same name are merged unless non-unique sections are
+// allowed, in which case each occurrence is kept separately.
+type iniLineIndex map[string][]*iniSectionLines
+
+type iniSectionLines struct {
+	// Line of the first header of the section, 0 for the default section
+	Line int
+	// First and last lines of the section, including its header and values
+	StartLine int
+	EndLine   int
+	// Lines of every occurrence of each key in the section
+	Keys map[string][]int
+}
+
+// section returns the lines recorded for the given occurrence of the named
+// section, or an empty set if the section was not found.
+func (idx iniLineIndex) section(name string, occurrence int) *iniSectionLines {
+	if s := idx[name]; occurrence < len(s) {
+		return s[occurrence]
+	}
+	return &iniSectionLines{Keys: map[string][]int{}}
+}
+
+// keyLine returns the line of the given occurrence of a key. If the number of
+// occurrences does not match the number of lines found, e.g. a repeated key
+// when shadows are not allowed, the line of the last occurrence is returned
+// as that is the value kept by go-ini.
+func (s *iniSectionLines) keyLine(key string, occurrence int, occurrences int) int {
+	lines := s.Keys[key]
+	if len(lines) == 0 {
+		return 0
+	}
+	if len(lines) == occurrences && occurrence < len(lines) {
+		return lines[occurrence]
+	}
+	return lines[len(lines)-1]
+}
+
+// indexINILines scans the content of an INI file, following the same rules
+// as the go-ini parser for the given options, and records the line number of
+// every section header and key.
+func indexINILines(content []byte, opts ini.LoadOptions) iniLineIndex {
+	insensitiveSections := opts.Insensitive || opts.InsensitiveSections
+	insensitiveKeys := opts.Insensitive || opts.InsensitiveKeys
+	delimiters := opts.KeyValueDelimiters
+	if delimiters == "" {
+		delimiters = "=:"
+	}
+
+	idx := iniLineIndex{}
+	addSection := func(name string, line int) *iniSectionLines {
+		if s, ok := idx[name]; ok && !opts.AllowNonUniqueSections {
+			return s[0]
+		}
+		s := &iniSectionLines{Line: line, StartLine: line, EndLine: line, Keys: map[string][]int{}}
+		idx[name] = append(idx[name], s)
+		return s
+	}
+
+	name := ini.DefaultSection
+	if insensitiveSections {
+		name = strings.ToLower(name)
+	}
+	section := addSection(name, 0)
+
+	var closingQuote string
+	var inContinuation, inPythonMultiline, inUnparseableSection bool
+	autoIncrement := 1
+
+	lines := strings.Split(strings.TrimPrefix(string(content), "\uFEFF"), "\n")
+	for i, raw := range lines {
+		lineNumber := i + 1
+
+		// Skip the remaining lines of multi-line values
+		switch {
+		case closingQuote != "":
+			if strings.Contains(raw, closingQuote) {
+				closingQuote = ""
+			}
+			section.EndLine = lineNumber
+			continue
+		case inContinuation:
+			next := strings.TrimSpace(raw)
+			inContinuation = next != "" && strings.HasSuffix(next, "\\")
+			if next != "" {
+				section.EndLine = lineNumber
+			}
+			continue
+		case inPythonMultiline:
+			if raw != "" && strings.ContainsRune(" \t\f", rune(raw[0])) {
+				if strings.TrimSpace(raw) != "" {
+					section.EndLine = lineNumber
+				}
+				continue
+			}
+			inPythonMultiline = false
+		}
+
+		line := strings.TrimLeftFunc(raw, unicode.IsSpace)
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if line[0] == '[' {
+			closeIdx := strings.LastIndexByte(line, ']')
+			if closeIdx == -1 {
+				continue
+			}
+			name := line[1:closeIdx]
+			inUnparseableSection = false
+			for _, i := range opts.UnparseableSections {
+				if i == name || (insensitiveSections && strings.EqualFold(i, name)) {
+					inUnparseableSection = true
+				}
+			}
+			if insensitiveSections && name != ini.DefaultSection {
+				name = strings.ToLower(name)
+			}
+			section = addSection(name, lineNumber)
+			section.EndLine = lineNumber
+			autoIncrement = 1
+			continue
+		}
+
+		section.EndLine = lineNumber
+		if section.StartLine == 0 {
+			section.StartLine = lineNumber
+		}
+		if inUnparseableSection {
+			continue
+		}
+
+		key, offset, ok := iniKeyName(delimiters, line)
+		if !ok {
+			if !opts.AllowBooleanKeys {
+				continue
+			}
+			key = strings.TrimSpace(stripINIInlineComment(strings.TrimSpace(line), opts))
+		} else {
+			if key == "-" {
+				key = "#" + strconv.Itoa(autoIncrement)
+				autoIncrement++
+			}
+
+			value := strings.TrimLeftFunc(line[offset:], unicode.IsSpace)
+			hasNextLine := i < len(lines)-1
+			switch {
+			case value == "":
+				inPythonMultiline = opts.AllowPythonMultilineValues && hasNextLine
+			case len(value) > 3 && strings.HasPrefix(value, `"""`):
+				if !strings.Contains(value[3:], `"""`) {
+					closingQuote = `"""`
+				}
+			case value[0] == '`':
+				if !strings.Contains(value[1:], "`") {
+					closingQuote = "`"
+				}
+			case !opts.IgnoreContinuation && strings.HasSuffix(strings.TrimSpace(value), "\\"):
+				inContinuation = true
+			default:
+				value = stripINIInlineComment(strings.TrimSpace(value), opts)
+				quoted := len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0]
+				inPythonMultiline = opts.AllowPythonMultilineValues && hasNextLine && !quoted
+			}
+		}
+
+		if insensitiveKeys {
+			key = strings.ToLower(key)
+		}
+		section.Keys[key] = append(section.Keys[key], lineNumber)
+	}
+	return idx
+}
+
+// iniKeyName extracts the key name from a key/value line and returns the
+// offset of the value, mirroring the go-ini parser.
+func iniKeyName(delimiters string, line string) (string, int, bool) {
+	var keyQuote string
+	if line[0] == '"' {
+		if len(line) > 6 && line[0:3] == `"""` {
+			keyQuote = `"""`
+		} else {
+			keyQuote = `"`
+		}
+	} else if line[0] == '`' {
+		keyQuote = "`"
+	}
+
+	if keyQuote != "" {
+		startIdx := len(keyQuote)
+		pos := strings.Index(line[startIdx:], keyQuote)
+		if pos == -1 {
+			return "", -1, false
+		}
+		pos += startIdx
+		i := strings.IndexAny(line[pos+startIdx:], delimiters)
+		if i < 0 {
+			return "", -1, false
+		}
+		return strings.TrimSpace(line[startIdx:pos]), pos + i + startIdx + 1, true
+	}
+
+	endIdx := strings.IndexAny(line, delimiters)
+	if endIdx < 0 {
+		return "", -1, false
+	}
+	return strings.TrimSpace(line[0:endIdx]), endIdx + 1, true
+}
+
+// stripINIInlineComment removes a trailing comment from a value.
+func stripINIInlineComment(value string, opts ini.LoadOptions) string {
+	if opts.IgnoreInlineComment {
+		return value
+	}
+	i := strings.IndexAny(value, "#;")
+	if opts.SpaceBeforeInlineComment {
+		i = strings.Index(value, " #")
+		if i == -1 {
+			i = strings.Index(value, " ;")
+		}
+	}
+	if i > -1 {
+		return strings.TrimSpace(value[:i])
+	}
+	return value
+}
+
 func listXMLFiles(ctx context.Context, d *plugin.QueryData) ([]string, error) {
 	cfg := GetConfig(d.Connection)
 	return listFilesByType(ctx, d, cfg.XMLPaths, "xml_paths must be configured to query XML files")
@@ -83,7 +372,232 @@ func listJSONFiles(ctx context.Context, d *plugin.QueryData) ([]string, error) {
 	return listFilesByType(ctx, d, cfg.JSONPaths, "json_paths must be configured to query JSON files")
 }
 
+func listJsonnetFiles(ctx context.Context, d *plugin.QueryData) ([]string, error) {
+	cfg := GetConfig(d.Connection)
+	return listFilesByType(ctx, d, cfg.JsonnetPaths, "jsonnet_paths must be configured to query Jsonnet files")
+}
+
+func listJSONLFiles(ctx context.Context, d *plugin.QueryData) ([]string, error) {
+	cfg := GetConfig(d.Connection)
+	return listFilesByType(ctx, d, cfg.JSONLPaths, "jsonl_paths must be configured to query JSON Lines files")
+}
+
+func listPlistFiles(ctx context.Context, d *plugin.QueryData) ([]string, error) {
+	cfg := GetConfig(d.Connection)
+	return listFilesByType(ctx, d, cfg.PlistPaths, "plist_paths must be configured to query plist files")
+}
+
+func listRegFiles(ctx context.Context, d *plugin.QueryData) ([]string, error) {
+	cfg := GetConfig(d.Connection)
+	return listFilesByType(ctx, d, cfg.RegPaths, "reg_paths must be configured to query registry export files")
+}
+
+func listKubernetesFiles(ctx context.Context, d *plugin.QueryData) ([]string, error) {
+	cfg := GetConfig(d.Connection)
+	return listFilesByType(ctx, d, cfg.KubernetesPaths, "kubernetes_paths must be configured to query Kubernetes manifests")
+}
+
+func listMavenFiles(ctx context.Context, d *plugin.QueryData) ([]string, error) {
+	cfg := GetConfig(d.Connection)
+	return listFilesByType(ctx, d, cfg.MavenPaths, "maven_paths must be configured to query Maven POM files")
+}
+
+func listCUEFiles(ctx context.Context, d *plugin.QueryData) ([]string, error) {
+	cfg := GetConfig(d.Connection)
+	return listFilesByType(ctx, d, cfg.CUEPaths, "cue_paths must be configured to query CUE files")
+}
+
+func listDependencyFiles(ctx context.Context, d *plugin.QueryData) ([]string, error) {
+	cfg := GetConfig(d.Connection)
+	return listFilesByType(ctx, d, cfg.DependencyPaths, "dependency_paths must be configured to query package manifest files")
+}
+
+func listLockfiles(ctx context.Context, d *plugin.QueryData) ([]string, error) {
+	cfg := GetConfig(d.Connection)
+	return listFilesByType(ctx, d, cfg.LockfilePaths, "lockfile_paths must be configured to query lockfiles")
+}
+
+func listDockerComposeFiles(ctx context.Context, d *plugin.QueryData) ([]string, error) {
+	cfg := GetConfig(d.Connection)
+	return listFilesByType(ctx, d, cfg.DockerComposePaths, "docker_compose_paths must be configured to query Docker Compose services")
+}
+
+func listDockerfileFiles(ctx context.Context, d *plugin.QueryData) ([]string, error) {
+	cfg := GetConfig(d.Connection)
+	return listFilesByType(ctx, d, cfg.DockerfilePaths, "dockerfile_paths must be configured to query Dockerfiles")
+}
+
+func listGitHubWorkflowFiles(ctx context.Context, d *plugin.QueryData) ([]string, error) {
+	cfg := GetConfig(d.Connection)
+	return listFilesByType(ctx, d, cfg.GitHubWorkflowPaths, "github_workflow_paths must be configured to query GitHub workflows")
+}
+
+func listApacheFiles(ctx context.Context, d *plugin.QueryData) ([]string, error) {
+	cfg := GetConfig(d.Connection)
+	return listFilesByType(ctx, d, cfg.ApachePaths, "apache_paths must be configured to query Apache httpd config files")
+}
+
+func listNginxFiles(ctx context.Context, d *plugin.QueryData) ([]string, error) {
+	cfg := GetConfig(d.Connection)
+	return listFilesByType(ctx, d, cfg.NginxPaths, "nginx_paths must be configured to query nginx config files")
+}
+
+func listSSHConfigFiles(ctx context.Context, d *plugin.QueryData) ([]string, error) {
+	cfg := GetConfig(d.Connection)
+	return listFilesByType(ctx, d, cfg.SSHConfigPaths, "ssh_config_paths must be configured to query SSH config files")
+}
+
+func listSystemdFiles(ctx context.Context, d *plugin.QueryData) ([]string, error) {
+	cfg := GetConfig(d.Connection)
+	return listFilesByType(ctx, d, cfg.SystemdPaths, "systemd_paths must be configured to query systemd units")
+}
+
 func listTOMLFiles(ctx context.Context, d *plugin.QueryData) ([]string, error) {
 	cfg := GetConfig(d.Connection)
 	return listFilesByType(ctx, d, cfg.TOMLPaths, "toml_paths must be configured to query TOML files")
 }
+
+// flattenConfigContent parses the content of a config file, based on the
+// extension of its path, and flattens it into the same key/value rows as the
+// yml_key_value table. Line numbers are only available for YML, JSON and INI
+// files.
+func flattenConfigContent(d *plugin.QueryData, path string, content []byte) (Rows, error) {
+	root, err := parseConfigContent(d, path, content)
+	if err != nil {
+		return nil, err
+	}
+	var rows Rows
+	treeToList(root, []string{}, &rows, nil, nil, nil, false)
+	return rows, nil
+}
+
+// parseConfigContent parses the content of a config file, based on the
+// extension of its path, into a YAML node tree.
+func parseConfigContent(d *plugin.QueryData, path string, content []byte) (*yaml.Node, error) {
+	var root yaml.Node
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json", ".jsonc", ".json5":
+		return loadJSONNode(d, path, content)
+	case ".yml", ".yaml":
+		if err := yaml.Unmarshal(content, &root); err != nil {
+			return nil, err
+		}
+	case ".toml":
+		var data interface{}
+		if err := toml.Unmarshal(content, &data); err != nil {
+			return nil, err
+		}
+		if err := root.Encode(data); err != nil {
+			return nil, err
+		}
+	case ".xml":
+		data, err := xmlToMap(content, GetConfig(d.Connection).XMLOptions)
+		if err != nil {
+			return nil, err
+		}
+		if err := root.Encode(data); err != nil {
+			return nil, err
+		}
+	case ".ini":
+		return parseININode(d, content)
+	default:
+		return nil, fmt.Errorf("unsupported file type %q", ext)
+	}
+	return &root, nil
+}
+
+// parseININode converts an INI file into a mapping of sections to mappings of
+// keys, with line numbers. Repeated keys are converted to sequences.
+func parseININode(d *plugin.QueryData, content []byte) (*yaml.Node, error) {
+	cfg, lines, err := loadINIContent(d, content)
+	if err != nil {
+		return nil, err
+	}
+	shadows, err := loadINIShadows(d, content, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	scalar := func(value string, line int) *yaml.Node {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, Line: line}
+	}
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: 1}
+	occurrences := map[string]int{}
+	for _, section := range cfg.Sections() {
+		sectionLines := lines.section(section.Name(), occurrences[section.Name()])
+		shadowSection := iniShadowSection(shadows, section, occurrences[section.Name()])
+		occurrences[section.Name()]++
+
+		sectionNode := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: sectionLines.StartLine}
+		for _, key := range section.Keys() {
+			values := iniKeyValues(shadowSection, key)
+			nodes := make([]*yaml.Node, len(values))
+			for i, v := range values {
+				value, _ := parseValue(cfg, section.Name(), key.Name(), v)
+				nodes[i] = scalar(value, sectionLines.keyLine(key.Name(), i, len(values)))
+			}
+			valueNode := nodes[0]
+			if len(nodes) > 1 {
+				valueNode = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: nodes[0].Line, Content: nodes}
+			}
+			sectionNode.Content = append(sectionNode.Content, scalar(key.Name(), valueNode.Line), valueNode)
+		}
+		root.Content = append(root.Content, scalar(section.Name(), sectionLines.StartLine), sectionNode)
+	}
+	return root, nil
+}
+
+// numberRegex matches decimal numbers, excluding forms accepted by
+// strconv.ParseFloat which are unlikely to be intended as numbers in config
+// files, e.g. hex floats, Inf and NaN.
+var numberRegex = regexp.MustCompile(`^[-+]?(\d+\.?\d*|\.\d+)([eE][-+]?\d+)?$`)
+
+// timestampLayouts are the layouts tried when coercing a string to a timestamp.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseNumber returns the number represented by str, or nil if it is not a
+// decimal number.
+func parseNumber(str string) *float64 {
+	str = strings.TrimSpace(str)
+	if !numberRegex.MatchString(str) {
+		return nil
+	}
+	f, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+// parseBool returns the boolean represented by str, using the same values as
+// go-ini, or nil if it is not a boolean.
+func parseBool(str string) *bool {
+	var b bool
+	switch strings.TrimSpace(str) {
+	case "1", "t", "T", "true", "TRUE", "True", "YES", "yes", "Yes", "y", "ON", "on", "On":
+		b = true
+	case "0", "f", "F", "false", "FALSE", "False", "NO", "no", "No", "n", "OFF", "off", "Off":
+		b = false
+	default:
+		return nil
+	}
+	return &b
+}
+
+// parseTimestamp returns the time represented by str, or nil if it is not
+// an RFC 3339 timestamp or date.
+func parseTimestamp(str string) *time.Time {
+	str = strings.TrimSpace(str)
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, str); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
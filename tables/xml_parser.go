@@ -0,0 +1,323 @@
+package config
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	// xmlDefaultMaxDepth is the maximum nesting of elements unless set by the
+	// max_depth option, to guard against stack exhaustion.
+	xmlDefaultMaxDepth = 256
+
+	// xmlMaxEntityExpansion is the maximum number of bytes entity references
+	// may expand to in a document, to guard against billion laughs and
+	// quadratic blowup attacks.
+	xmlMaxEntityExpansion = 10 << 20
+
+	// xmlMaxEntityDepth is the maximum nesting of entity references in the
+	// replacement text of entities.
+	xmlMaxEntityDepth = 16
+)
+
+// xmlToMap converts an XML document into a map with the root element as its
+// only key, in the format of mxj.NewMapXml with its default settings, applying
+// the xml_options of the connection. Attributes are prefixed keys of the map of
+// their element, repeated elements become arrays, and the text of elements
+// with attributes or child elements is kept under the text key.
+func xmlToMap(content []byte, o *xmlOptions) (map[string]interface{}, error) {
+	decoder, err := newXMLDecoder(content, o)
+	if err != nil {
+		return nil, err
+	}
+	c := xmlConverter{decoder: decoder, options: o}
+	for {
+		t, err := decoder.RawToken()
+		if err == io.EOF {
+			return nil, errors.New("no root element")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := t.(xml.StartElement); ok {
+			value, err := c.element(start, 1)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{c.name(start.Name): value}, nil
+		}
+	}
+}
+
+// newXMLDecoder returns a decoder for an XML document in the configured
+// strictness. Entities declared in the internal subset of the DOCTYPE are
+// only expanded if the expand_entities option is set. External entities are
+// never loaded.
+func newXMLDecoder(content []byte, o *xmlOptions) (*xml.Decoder, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	decoder.Strict = o.strict()
+	// Documents in other encodings than UTF-8, e.g. ISO-8859-1, are decoded as
+	// in parseXMLDocument
+	decoder.CharsetReader = charset.NewReaderLabel
+	if o != nil && o.ExpandEntities {
+		entities, err := xmlInternalEntities(content)
+		if err != nil {
+			return nil, err
+		}
+		decoder.Entity = entities
+	}
+	return decoder, nil
+}
+
+// parseXMLDocument parses an XML document into a tree of nodes for XPath
+// queries, with line numbers, applying the strict, max_depth and
+// expand_entities options of the connection.
+func parseXMLDocument(content []byte, o *xmlOptions) (*xmlquery.Node, error) {
+	decoderOptions := &xmlquery.DecoderOptions{
+		Strict:        o.strict(),
+		CharsetReader: charset.NewReaderLabel,
+	}
+	if o != nil && o.ExpandEntities {
+		entities, err := xmlInternalEntities(content)
+		if err != nil {
+			return nil, err
+		}
+		decoderOptions.Entity = entities
+	}
+	doc, err := xmlquery.ParseWithOptions(bytes.NewReader(content), xmlquery.ParserOptions{
+		Decoder:         decoderOptions,
+		WithLineNumbers: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the depth without recursion, since the tree is already parsed
+	type entry struct {
+		node  *xmlquery.Node
+		depth int
+	}
+	stack := []entry{{doc, 0}}
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if e.depth > o.maxDepth() {
+			return nil, fmt.Errorf("elements are nested deeper than the max_depth of %d", o.maxDepth())
+		}
+		for c := e.node.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == xmlquery.ElementNode {
+				stack = append(stack, entry{c, e.depth + 1})
+			}
+		}
+	}
+	return doc, nil
+}
+
+// xmlEscape returns the text escaped for use in XML content or attribute
+// values.
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+type xmlConverter struct {
+	decoder *xml.Decoder
+	options *xmlOptions
+}
+
+// name returns the key of an element or attribute name, with its namespace
+// prefix if the keep_namespaces option is set.
+func (c xmlConverter) name(name xml.Name) string {
+	if c.options != nil && c.options.KeepNamespaces && name.Space != "" {
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
+}
+
+func (c xmlConverter) element(start xml.StartElement, depth int) (interface{}, error) {
+	if depth > c.options.maxDepth() {
+		return nil, fmt.Errorf("elements are nested deeper than the max_depth of %d", c.options.maxDepth())
+	}
+
+	children := map[string]interface{}{}
+	for _, a := range start.Attr {
+		children[c.options.attributePrefix()+c.name(a.Name)] = c.options.cast(a.Value)
+	}
+
+	var text []string
+	for {
+		t, err := c.decoder.RawToken()
+		if err == io.EOF {
+			return nil, fmt.Errorf("element <%s> is not closed", c.name(start.Name))
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			value, err := c.element(t, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			key := c.name(t.Name)
+			switch existing := children[key].(type) {
+			case nil:
+				children[key] = value
+			case []interface{}:
+				children[key] = append(existing, value)
+			default:
+				children[key] = []interface{}{existing, value}
+			}
+		case xml.EndElement:
+			if c.options.strict() && (t.Name != start.Name) {
+				line, _ := c.decoder.InputPos()
+				return nil, fmt.Errorf("line %d: element <%s> closed by </%s>", line, c.name(start.Name), c.name(t.Name))
+			}
+			s := strings.Join(text, " ")
+			switch {
+			case len(children) == 0 && s == "":
+				return "", nil
+			case len(children) == 0:
+				return c.options.cast(s), nil
+			case s != "":
+				children[c.options.textKey()] = c.options.cast(s)
+			}
+			return children, nil
+		case xml.CharData:
+			if s := strings.Trim(string(t), "\t\r\b\n "); s != "" {
+				text = append(text, s)
+			}
+		}
+	}
+}
+
+// xmlEntityDeclRegex matches the declarations of internal general entities.
+// Parameter entities and external entities, with a SYSTEM or PUBLIC
+// identifier, are not matched.
+var xmlEntityDeclRegex = regexp.MustCompile(`<!ENTITY\s+([^\s%]+)\s+(?:"([^"]*)"|'([^']*)')\s*>`)
+
+var xmlEntityRefRegex = regexp.MustCompile(`&([A-Za-z_:][A-Za-z0-9_:.-]*);`)
+
+// xmlInternalEntities returns the replacement text of the internal entities
+// declared in the DOCTYPE of a document, with references to other entities
+// expanded. It fails if the entities of the document would expand to more
+// than xmlMaxEntityExpansion bytes.
+func xmlInternalEntities(content []byte) (map[string]string, error) {
+	subset := xmlInternalSubset(content)
+	if subset == "" {
+		return nil, nil
+	}
+	declared := map[string]string{}
+	for _, m := range xmlEntityDeclRegex.FindAllStringSubmatch(subset, -1) {
+		// The first declaration of an entity is binding
+		if _, ok := declared[m[1]]; !ok {
+			declared[m[1]] = m[2] + m[3]
+		}
+	}
+
+	entities := map[string]string{}
+	var expand func(name string, depth int) (string, error)
+	expand = func(name string, depth int) (string, error) {
+		if v, ok := entities[name]; ok {
+			return v, nil
+		}
+		if depth > xmlMaxEntityDepth {
+			return "", fmt.Errorf("entity &%s; is nested too deeply or refers to itself", name)
+		}
+		var err error
+		v := xmlEntityRefRegex.ReplaceAllStringFunc(declared[name], func(ref string) string {
+			ref = ref[1 : len(ref)-1]
+			if _, ok := declared[ref]; !ok || err != nil {
+				return "&" + ref + ";"
+			}
+			var s string
+			s, err = expand(ref, depth+1)
+			return s
+		})
+		if err != nil {
+			return "", err
+		}
+		if len(v) > xmlMaxEntityExpansion {
+			return "", fmt.Errorf("entity &%s; expands to more than %d bytes", name, xmlMaxEntityExpansion)
+		}
+		entities[name] = v
+		return v, nil
+	}
+	for name := range declared {
+		if _, err := expand(name, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	// Limit the total size of the references in the document
+	total := 0
+	for _, m := range xmlEntityRefRegex.FindAllSubmatch(content, -1) {
+		total += len(entities[string(m[1])])
+		if total > xmlMaxEntityExpansion {
+			return nil, fmt.Errorf("entity references expand to more than %d bytes", xmlMaxEntityExpansion)
+		}
+	}
+	return entities, nil
+}
+
+// xmlInternalSubset returns the internal subset of the DOCTYPE declaration of
+// a document, i.e. the declarations between its square brackets.
+func xmlInternalSubset(content []byte) string {
+	s := string(content)
+	i := strings.Index(s, "<!DOCTYPE")
+	if i < 0 {
+		return ""
+	}
+	s = s[i:]
+	var quote byte
+	start := -1
+	for n := 0; n < len(s); n++ {
+		switch c := s[n]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' && start < 0:
+			start = n + 1
+		case c == ']' && start >= 0:
+			return s[start:n]
+		case c == '>' && start < 0:
+			return ""
+		}
+	}
+	return ""
+}
+
+// cast converts numbers and booleans if the cast_values option is set, as
+// mxj did. NaN and infinity are kept as strings.
+func (o *xmlOptions) cast(s string) interface{} {
+	if o == nil || !o.CastValues {
+		return s
+	}
+	switch strings.ToLower(strings.TrimLeft(s, "+-")) {
+	case "nan", "inf", "infinity":
+		return s
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	switch s {
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	return s
+}